@@ -0,0 +1,210 @@
+package cfg
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// fileFormat describes how keys and sections are written in one of the supported configuration formats.
+type fileFormat struct {
+	separator string
+	// section returns the header line of a profile section. It is nil when the format has no section headers.
+	section func(profile string) string
+	quote   bool
+}
+
+func formatOf(path string) fileFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return fileFormat{separator: " = ", quote: true, section: func(profile string) string {
+			return "[" + profilesKey + "." + profile + "]"
+		}}
+	case ".yaml", ".yml":
+		return fileFormat{separator: ": ", quote: true}
+	}
+	return fileFormat{separator: "=", section: func(profile string) string {
+		return "[" + profile + "]"
+	}}
+}
+
+// SetValue method sets the key to the value in the configuration file at path, keeping its comments and layout.
+// The key is replaced in place when it is already set in the section of the profile, otherwise it is added to
+// the end of the section. An empty profile or "default" means the keys given outside of any profile.
+// YAML files can only be edited at their top level.
+func SetValue(path string, profile string, key string, value string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	format := formatOf(path)
+	if profile == defaultProfile {
+		profile = ""
+	}
+	header := ""
+	if profile != "" {
+		if format.section == nil {
+			return errors.New("Profiles of " + path + " can not be edited by lamp, edit the file directly")
+		}
+		header = format.section(profile)
+	}
+
+	var lines []string
+	if len(content) > 0 {
+		lines = strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	}
+	formatted := formatValueForFile(format, value)
+
+	// insertAt is the line after the last key of the section, or -1 while the section is not found
+	inSection := header == ""
+	insertAt := -1
+	sectionEnd := len(lines)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if isSectionHeader(format, line) {
+			if inSection {
+				sectionEnd = i
+				if header == "" {
+					break
+				}
+			}
+			inSection = trimmed == header
+			if inSection {
+				insertAt = i + 1
+			}
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		if trimmed != "" && !isComment(trimmed) {
+			insertAt = i + 1
+		}
+		lineKey, valueAt := splitKey(format, line)
+		if lineKey == "" {
+			continue
+		}
+		if lineKey == key {
+			if valueAt < len(line) || format.section != nil {
+				lines[i] = line[:valueAt] + formatted + inlineComment(format, line[valueAt:])
+				return writeLines(path, lines)
+			}
+			if i+1 < len(lines) && (strings.HasPrefix(lines[i+1], " ") || strings.HasPrefix(lines[i+1], "\t")) {
+				return errors.New(key + " holds nested settings in " + path + ", edit the file directly")
+			}
+			lines[i] = strings.TrimRight(line, " \t") + " " + formatted
+			return writeLines(path, lines)
+		}
+		if format.section == nil && strings.HasPrefix(key, lineKey+".") {
+			return errors.New(key + " is nested under " + lineKey + " in " + path + ", edit the file directly")
+		}
+	}
+
+	entry := key + format.separator + formatted
+	if format.quote && format.separator == " = " && strings.Contains(key, ".") {
+		entry = strconv.Quote(key) + format.separator + formatted
+	}
+	if insertAt < 0 && header == "" {
+		insertAt = sectionEnd
+		for insertAt > 0 && strings.TrimSpace(lines[insertAt-1]) == "" {
+			insertAt--
+		}
+	}
+	if insertAt < 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, header, entry)
+	} else {
+		lines = append(lines[:insertAt], append([]string{entry}, lines[insertAt:]...)...)
+	}
+	return writeLines(path, lines)
+}
+
+func isComment(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";")
+}
+
+func isSectionHeader(format fileFormat, line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if format.section == nil {
+		// the top level keys of YAML files start at the first column
+		return false
+	}
+	return strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]")
+}
+
+// splitKey returns the key set on the line and the offset its value starts at, or an empty key
+// when the line does not set a key.
+func splitKey(format fileFormat, line string) (string, int) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || isComment(trimmed) {
+		return "", 0
+	}
+	if format.section == nil && (line[0] == ' ' || line[0] == '\t' || trimmed[0] == '-') {
+		return "", 0
+	}
+	separator := strings.TrimSpace(format.separator)
+	index := strings.Index(line, separator)
+	if index < 0 {
+		return "", 0
+	}
+	key := strings.Trim(strings.TrimSpace(line[:index]), `"'`)
+	valueAt := index + len(separator)
+	for valueAt < len(line) && (line[valueAt] == ' ' || line[valueAt] == '\t') {
+		valueAt++
+	}
+	return key, valueAt
+}
+
+// inlineComment returns the comment that follows the value of a YAML or TOML line, with the spaces before it.
+// Values of conf files can not have inline comments.
+func inlineComment(format fileFormat, value string) string {
+	if !format.quote {
+		return ""
+	}
+	var quote byte
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && i > 0 && (value[i-1] == ' ' || value[i-1] == '\t'):
+			start := i
+			for start > 0 && (value[start-1] == ' ' || value[start-1] == '\t') {
+				start--
+			}
+			return value[start:]
+		}
+	}
+	return ""
+}
+
+func formatValueForFile(format fileFormat, value string) string {
+	if !format.quote {
+		return value
+	}
+	if _, err := strconv.ParseBool(value); err == nil {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+func writeLines(path string, lines []string) error {
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), mode)
+}
@@ -0,0 +1,169 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		profile string
+		key     string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "conf replaces the key in place",
+			file:    "lamp.conf",
+			content: "# OpsGenie\napiKey=old\n\n## proxy\nproxyHost=proxy.local\n",
+			key:     "apiKey",
+			value:   "new",
+			want:    "# OpsGenie\napiKey=new\n\n## proxy\nproxyHost=proxy.local\n",
+		},
+		{
+			name:    "conf adds a key before the first section",
+			file:    "lamp.conf",
+			content: "# OpsGenie\napiKey=old\n\n# EU account\n[eu]\napiKey=eu\n",
+			key:     "user",
+			value:   "jane@example.com",
+			want:    "# OpsGenie\napiKey=old\nuser=jane@example.com\n\n# EU account\n[eu]\napiKey=eu\n",
+		},
+		{
+			name:    "conf replaces the key in the profile",
+			file:    "lamp.conf",
+			content: "apiKey=default\n\n[eu]\n# EU key\napiKey=old\n",
+			profile: "eu",
+			key:     "apiKey",
+			value:   "new",
+			want:    "apiKey=default\n\n[eu]\n# EU key\napiKey=new\n",
+		},
+		{
+			name:    "conf adds the key to the end of the profile",
+			file:    "lamp.conf",
+			content: "[eu]\napiKey=eu\n# trailing comment of eu\n\n[sandbox]\napiKey=sandbox\n",
+			profile: "eu",
+			key:     "region",
+			value:   "eu",
+			want:    "[eu]\napiKey=eu\nregion=eu\n# trailing comment of eu\n\n[sandbox]\napiKey=sandbox\n",
+		},
+		{
+			name:    "conf adds a missing profile",
+			file:    "lamp.conf",
+			content: "# OpsGenie\napiKey=default\n",
+			profile: "eu",
+			key:     "apiKey",
+			value:   "eu",
+			want:    "# OpsGenie\napiKey=default\n\n[eu]\napiKey=eu\n",
+		},
+		{
+			name:    "commented out key is not replaced",
+			file:    "lamp.conf",
+			content: "## apiKey=your_api_key\n",
+			key:     "apiKey",
+			value:   "new",
+			want:    "## apiKey=your_api_key\napiKey=new\n",
+		},
+		{
+			name:    "yaml replaces the key in place",
+			file:    "lamp.yaml",
+			content: "# OpsGenie\napiKey: old # production\nuser: jane@example.com\n",
+			key:     "apiKey",
+			value:   "new",
+			want:    "# OpsGenie\napiKey: \"new\" # production\nuser: jane@example.com\n",
+		},
+		{
+			name:    "yaml keeps a hash inside quotes",
+			file:    "lamp.yaml",
+			content: "apiKey: \"old # not a comment\"  # production\n",
+			key:     "apiKey",
+			value:   "new",
+			want:    "apiKey: \"new\"  # production\n",
+		},
+		{
+			name:    "yaml does not quote numbers",
+			file:    "lamp.yaml",
+			content: "# timeouts\nrequestTimeout: 30\n",
+			key:     "requestTimeout",
+			value:   "60",
+			want:    "# timeouts\nrequestTimeout: 60\n",
+		},
+		{
+			name:    "yaml nested key",
+			file:    "lamp.yaml",
+			content: "opsgenie:\n  api:\n    url: https://api.opsgenie.com\n",
+			key:     "opsgenie.api.url",
+			value:   "https://api.eu.opsgenie.com",
+			wantErr: true,
+		},
+		{
+			name:    "yaml profile",
+			file:    "lamp.yaml",
+			content: "apiKey: default\n",
+			profile: "eu",
+			key:     "apiKey",
+			value:   "eu",
+			wantErr: true,
+		},
+		{
+			name:    "toml quotes dotted keys",
+			file:    "lamp.toml",
+			content: "# OpsGenie\napiKey = \"old\"\n\n[profiles.eu]\napiKey = \"eu\"\n",
+			key:     "opsgenie.api.url",
+			value:   "https://api.eu.opsgenie.com",
+			want:    "# OpsGenie\napiKey = \"old\"\n\"opsgenie.api.url\" = \"https://api.eu.opsgenie.com\"\n\n[profiles.eu]\napiKey = \"eu\"\n",
+		},
+		{
+			name:    "toml replaces the key in the profile",
+			file:    "lamp.toml",
+			content: "apiKey = \"default\"\n\n[profiles.eu]\n# EU key\napiKey = \"old\" # rotated yearly\n",
+			profile: "eu",
+			key:     "apiKey",
+			value:   "new",
+			want:    "apiKey = \"default\"\n\n[profiles.eu]\n# EU key\napiKey = \"new\" # rotated yearly\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), test.file)
+			if err := ioutil.WriteFile(path, []byte(test.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			err := SetValue(path, test.profile, test.key, test.value)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("SetValue() returned no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != test.want {
+				t.Errorf("SetValue() wrote\n%s\nwant\n%s", content, test.want)
+			}
+		})
+	}
+}
+
+func TestSetValueCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lamp.conf")
+	if err := SetValue(path, "default", "apiKey", "new"); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "apiKey=new\n" {
+		t.Errorf("SetValue() wrote %q, want %q", content, "apiKey=new\n")
+	}
+}
@@ -0,0 +1,36 @@
+package cfg
+
+import (
+	"os"
+	"strings"
+	"unicode"
+)
+
+const envPrefix = "LAMP_"
+
+// EnvName method returns the environment variable that overrides the given configuration key.
+// The key is upper cased with its words separated by underscores and prefixed with LAMP_, dropping
+// a leading "lamp." so that apiKey becomes LAMP_API_KEY, opsgenie.api.url becomes LAMP_OPSGENIE_API_URL
+// and lamp.log.level becomes LAMP_LOG_LEVEL.
+func EnvName(key string) string {
+	key = strings.TrimPrefix(key, "lamp.")
+	var name []rune
+	var previous rune
+	for i, r := range key {
+		switch {
+		case r == '.' || r == '-' || r == '_':
+			r = '_'
+		case i > 0 && unicode.IsUpper(r) && (unicode.IsLower(previous) || unicode.IsDigit(previous)):
+			name = append(name, '_')
+		}
+		name = append(name, unicode.ToUpper(r))
+		previous = r
+	}
+	return envPrefix + string(name)
+}
+
+// getEnv returns the value of the environment variable that overrides the key, if it is set and not empty.
+func getEnv(key string) (string, bool) {
+	val := os.Getenv(EnvName(key))
+	return val, val != ""
+}
@@ -0,0 +1,43 @@
+package cfg
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "apiKey", want: "LAMP_API_KEY"},
+		{key: "apiKeyFile", want: "LAMP_API_KEY_FILE"},
+		{key: "opsgenie.api.url", want: "LAMP_OPSGENIE_API_URL"},
+		{key: "lamp.log.level", want: "LAMP_LOG_LEVEL"},
+		{key: "lamp.log.maxBackups", want: "LAMP_LOG_MAX_BACKUPS"},
+		{key: "proxy-host", want: "LAMP_PROXY_HOST"},
+		{key: "region", want: "LAMP_REGION"},
+		{key: "http2Enabled", want: "LAMP_HTTP2_ENABLED"},
+		{key: "URL", want: "LAMP_URL"},
+	}
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			if got := EnvName(test.key); got != test.want {
+				t.Errorf("EnvName(%q) = %q, want %q", test.key, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetEnv(t *testing.T) {
+	defer os.Unsetenv("LAMP_LOG_LEVEL")
+
+	os.Setenv("LAMP_LOG_LEVEL", "debug")
+	if val, found := getEnv("lamp.log.level"); !found || val != "debug" {
+		t.Errorf("getEnv() = %q, %t, want debug, true", val, found)
+	}
+	os.Setenv("LAMP_LOG_LEVEL", "")
+	if val, found := getEnv("lamp.log.level"); found {
+		t.Errorf("getEnv() = %q, %t, want an empty variable to be unset", val, found)
+	}
+}
@@ -0,0 +1,148 @@
+package cfg
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strings"
+)
+
+// knownKeys are the configuration keys lamp reads.
+var knownKeys = []string{
+	"apiKey",
+	"apiKeyFile",
+	"apiKeyCommand",
+	"user",
+	"opsgenie.api.url",
+	"region",
+	"proxyHost",
+	"proxyPort",
+	"proxyProtocol",
+	"proxyUsername",
+	"proxyPassword",
+	"proxyPasswordFile",
+	"proxyPasswordCommand",
+	"connectionTimeout",
+	"requestTimeout",
+	"lamp.log.level",
+	"lamp.log.file",
+	"lamp.log.format",
+	"lamp.log.output",
+	"lamp.log.maxSize",
+	"lamp.log.maxBackups",
+}
+
+var secretKeys = map[string]bool{
+	"apiKey":        true,
+	"proxyPassword": true,
+}
+
+// Setting is the effective value of a configuration key and the source it is read from.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// LoadedPaths method returns the paths of the configuration files that are read, the one with the highest
+// precedence last.
+func LoadedPaths() []string {
+	var paths []string
+	for _, file := range configFiles {
+		paths = append(paths, file.path)
+	}
+	return paths
+}
+
+// EffectiveSettings method returns the value and the source of every known key that is set and of every other
+// key in the configuration files, sorted by key. Secrets are redacted.
+func EffectiveSettings() []Setting {
+	keys := map[string]bool{}
+	for _, key := range knownKeys {
+		keys[key] = true
+	}
+	for _, file := range configFiles {
+		for _, section := range profileSections(activeProfile) {
+			for _, key := range file.keys(section) {
+				keys[key] = true
+			}
+		}
+	}
+
+	var settings []Setting
+	for key := range keys {
+		setting, found := describeKey(key)
+		if !found {
+			continue
+		}
+		if secretKeys[key] && !strings.HasPrefix(setting.Value, envSecretPrefix) {
+			setting.Value = redact(setting.Value)
+		}
+		settings = append(settings, setting)
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings
+}
+
+func describeKey(key string) (Setting, bool) {
+	if val, success := getEnv(key); success {
+		return Setting{Key: key, Value: val, Source: "env " + EnvName(key)}, true
+	}
+	if value, layer, found := lookupLayers(activeProfile, key); found {
+		return Setting{Key: key, Value: formatValue(value), Source: layer.source()}, true
+	}
+	return Setting{}, false
+}
+
+func redact(secret string) string {
+	if len(secret) <= 8 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
+
+// keys returns the keys set in the given section of the file. Nested settings of YAML and TOML files are
+// returned with their dotted keys, without the maps that hold them.
+func (f configFile) keys(section string) []string {
+	if s, ok := f.source.(*structuredConfig); ok {
+		var keys []string
+		for key, value := range s.sections[section] {
+			if _, nested := value.(map[string]interface{}); !nested {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		return keys
+	}
+	return f.confKeys[section]
+}
+
+// readConfKeys returns the keys set in each section of the conf file, in the order they are given.
+// The keys given before any section are returned under section "".
+func readConfKeys(path string) map[string][]string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	keys := map[string][]string{}
+	current := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || isComment(line) {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if index := strings.Index(line, "="); index > 0 {
+			keys[current] = append(keys[current], strings.TrimSpace(line[:index]))
+		}
+	}
+	return keys
+}
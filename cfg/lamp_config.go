@@ -8,6 +8,7 @@ license that can be found in the LICENSE file.
 package cfg
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -21,10 +22,26 @@ import (
 const (
 	confPath        = "LAMP_CONF_PATH"
 	logDir          = "LAMP_LOGS_DIR"
+	stateDir        = "LAMP_STATE_DIR"
 	sep      string = string(filepath.Separator)
 )
 
-var lampConfig *config.Config
+// configFile is a configuration file that is read. Files read later take precedence over the ones read earlier.
+type configFile struct {
+	path   string
+	source configSource
+	// confKeys are the keys set in each section of a conf file, in the order they are given.
+	confKeys map[string][]string
+}
+
+// configLayer is a section of a configuration file that keys are looked up in.
+type configLayer struct {
+	file    configFile
+	section string
+}
+
+var configFiles []configFile
+var loadErrors []error
 
 // Verbose is an exported variable to determine command is executing verbose mode or not.
 var Verbose = false
@@ -47,53 +64,250 @@ func lampHome() string {
 	return dir + sep
 }
 
+// StatePath method returns the path of the given file under the directory lamp keeps its state in.
+// The directory is LAMP_STATE_DIR if set, otherwise the state folder under lamp home if it exists,
+// otherwise $XDG_STATE_HOME/lamp.
+func StatePath(fileName string) string {
+	if dir := os.Getenv(stateDir); dir != "" {
+		return dir + sep + fileName
+	}
+	if dirExists(lampHome() + "state") {
+		return lampHome() + "state" + sep + fileName
+	}
+	return xdgStateHome() + sep + "lamp" + sep + fileName
+}
+
+// DefaultConfigPath method returns the path of the configuration file of the user, $XDG_CONFIG_HOME/lamp/lamp.conf.
+func DefaultConfigPath() string {
+	return xdgConfigHome() + sep + "lamp" + sep + "lamp.conf"
+}
+
 // LoadConfigFromGivenPath method reads configuration file from the given path.
 func LoadConfigFromGivenPath(confPath string) {
 	printVerboseMessage("Will read configuration from: \n--config " + confPath)
+	configFiles = nil
+	loadErrors = nil
+	resolvedSecrets = map[string]string{}
+	if _, err := os.Stat(confPath); err != nil {
+		loadErrors = append(loadErrors, errors.New("Could not read config file "+confPath+": "+err.Error()))
+		return
+	}
 	load(confPath)
 }
 
-// LoadConfiguration method tries to find and read the configuration file some specific paths.
+// LoadConfiguration method reads the configuration files found in the following order, the values of
+// a file taking precedence over the ones read before it:
+//
+//	/etc/lamp/
+//	<lamp home>/../conf/opsgenie-integration.conf
+//	<lamp home>/conf/
+//	~/.lamp/
+//	$XDG_CONFIG_HOME/lamp/, ~/.config/lamp/ by default
+//	LAMP_CONF_PATH
+//
+// In each directory the first of lamp.conf, lamp.yaml, lamp.yml and lamp.toml is read.
 func LoadConfiguration() {
-	confPath := os.Getenv(confPath)
-	if confPath == "" {
-		confPath = lampHome() + ".." + sep + "conf" + sep + "opsgenie-integration.conf"
-		printVerboseMessage("LAMP_CONF_PATH environment variable is not set. Will try to read config from: \n" + confPath)
+	configFiles = nil
+	loadErrors = nil
+	resolvedSecrets = map[string]string{}
+	for _, path := range configSearchPaths() {
+		if _, err := os.Stat(path); err != nil {
+			printVerboseMessage("Could not find config file: " + path)
+			continue
+		}
+		printVerboseMessage("Will read configuration from: \n" + path)
+		load(path)
+	}
+	if len(configFiles) == 0 {
+		printVerboseMessage("Could not find any configuration file.")
 	}
+}
 
-	if _, err := os.Stat(confPath); os.IsNotExist(err) {
-		confPath = lampHome() + "conf" + sep + "lamp.conf"
-		printVerboseMessage("Could not find the file specified. Will try to read config from: \n" + confPath)
+// configSearchPaths returns the configuration files to read, the ones with lower precedence first.
+func configSearchPaths() []string {
+	var paths []string
+	addDir := func(dir string) {
+		for _, name := range []string{"lamp.conf", "lamp.yaml", "lamp.yml", "lamp.toml"} {
+			path := dir + sep + name
+			if _, err := os.Stat(path); err == nil {
+				paths = append(paths, path)
+				return
+			}
+		}
+		paths = append(paths, dir+sep+"lamp.conf")
 	}
-	load(confPath)
+
+	addDir(sep + "etc" + sep + "lamp")
+	paths = append(paths, lampHome()+".."+sep+"conf"+sep+"opsgenie-integration.conf")
+	addDir(lampHome() + "conf")
+	if home := userHome(); home != "" {
+		addDir(home + sep + ".lamp")
+	}
+	addDir(xdgConfigHome() + sep + "lamp")
+	if path := os.Getenv(confPath); path != "" {
+		paths = append(paths, path)
+	} else {
+		printVerboseMessage("LAMP_CONF_PATH environment variable is not set.")
+	}
+	return paths
 }
 
 func load(confPath string) {
-	if _, err := os.Stat(confPath); !os.IsNotExist(err) {
-		conf := config.NewConfig(confPath)
-		conf.Read()
-		lampConfig = conf
-		configureLog()
-	} else {
+	if _, err := os.Stat(confPath); os.IsNotExist(err) {
 		printVerboseMessage("Could not read config file: " + err.Error())
+		return
+	}
+	if isStructuredConfigPath(confPath) {
+		conf, err := readStructuredConfig(confPath)
+		if err != nil {
+			loadErrors = append(loadErrors, errors.New("Could not parse config file "+confPath+": "+err.Error()))
+			return
+		}
+		configFiles = append(configFiles, configFile{path: confPath, source: conf})
+		return
+	}
+	conf := config.NewConfig(confPath)
+	conf.Read()
+	configFiles = append(configFiles, configFile{path: confPath, source: conf, confKeys: readConfKeys(confPath)})
+}
+
+// configLayers returns the sections keys are looked up in for the given profile, in order of precedence.
+// The files read later come first, and within a file the section of the profile comes before the default
+// sections, so a file with a higher precedence overrides every section of the files before it.
+func configLayers(profile string) []configLayer {
+	var layers []configLayer
+	for i := len(configFiles) - 1; i >= 0; i-- {
+		for _, section := range profileSections(profile) {
+			layers = append(layers, configLayer{file: configFiles[i], section: section})
+		}
+	}
+	return layers
+}
+
+// lookupLayers returns the value of the key in the layer with the highest precedence that sets it. A key that
+// is set to an empty value is found, so that a layer can clear the value of the layers below it.
+func lookupLayers(profile string, key string) (interface{}, configLayer, bool) {
+	for _, layer := range configLayers(profile) {
+		if value, found := layer.file.lookup(layer.section, key); found {
+			return value, layer, true
+		}
+	}
+	return nil, configLayer{}, false
+}
+
+// lookup returns the value of the key in the section of the file, and whether the key is set in it.
+func (f configFile) lookup(section string, key string) (interface{}, bool) {
+	if s, ok := f.source.(*structuredConfig); ok {
+		return s.lookup(section, key)
+	}
+	for _, confKey := range f.confKeys[section] {
+		if confKey == key {
+			return f.source.Get(section, key), true
+		}
+	}
+	return nil, false
+}
+
+// source describes the layer as the path of its file followed by its section.
+func (l configLayer) source() string {
+	return l.file.path + sectionSuffix(l.section)
+}
+
+func userHome() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
 	}
+	return home
+}
+
+func xdgConfigHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return userHome() + sep + ".config"
+}
+
+func xdgStateHome() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return dir
+	}
+	return userHome() + sep + ".local" + sep + "state"
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
 }
 
 // Get method returns the configuration properties value according to the key.
+// The LAMP_* environment variable of the key takes precedence over the configuration file, see EnvName,
+// and values of the selected profile take precedence over the default section.
 func Get(key string) string {
-	if lampConfig != nil {
-		return lampConfig.Get("", key)
+	if val, success := getEnv(key); success {
+		return val
 	}
-	return ""
+	return GetInProfile(activeProfile, key)
 }
 
-func configureLog() {
+// logConfigured is set once the logger is configured, so that it is not replaced by every client of a command.
+var logConfigured = false
+
+// ConfigureLog method configures the logger with the lamp.log.* keys of the selected profile, which are read from
+// the environment when no configuration file is found. Only the first call in a process configures it.
+func ConfigureLog() {
+	if logConfigured {
+		return
+	}
+	logConfigured = true
+
 	level := Get("lamp.log.level")
 	if level == "" {
 		level = "warn"
 		printVerboseMessage("Could not get log level from configuration, will use default \"warn\".")
 	}
 
+	settings := logSettings{
+		level:  level,
+		format: strings.ToLower(Get("lamp.log.format")),
+		output: strings.ToLower(Get("lamp.log.output")),
+	}
+	if settings.format == "" {
+		settings.format = logFormatText
+	}
+	if settings.output == "" {
+		settings.output = logOutputFile
+	}
+	if settings.output == logOutputSyslog && !syslogSupported {
+		fmt.Printf("Error occured while configuring logger: syslog is not supported on this platform\n")
+		return
+	}
+	if maxSize := Get("lamp.log.maxSize"); maxSize != "" {
+		size, err := parseSize(maxSize)
+		if err != nil {
+			fmt.Printf("Error occured while configuring logger: lamp.log.maxSize: %s\n", err.Error())
+			return
+		}
+		settings.maxSize = size
+	}
+	settings.maxBackups = GetInt("lamp.log.maxBackups", 0)
+
+	if settings.output == logOutputFile {
+		settings.path = logPath()
+	} else {
+		printVerboseMessage("Will write logs to " + settings.output)
+	}
+
+	logConfig := logTemplate(settings)
+	logger, err := seelog.LoggerFromConfigAsBytes([]byte(logConfig))
+	if err != nil {
+		fmt.Printf("Error occured while configuring logger: %s\n", err.Error())
+		return
+	}
+	log.UseLogger(logger)
+}
+
+func logPath() string {
 	logDir := os.Getenv(logDir)
 
 	var outPath string
@@ -105,27 +319,12 @@ func configureLog() {
 	if logDir != "" {
 		outPath = logDir + sep + logFile
 		printVerboseMessage("Will write logs to: \n" + outPath)
-	} else {
+	} else if dirExists(lampHome() + "logs") {
 		outPath = lampHome() + "logs" + sep + logFile
 		printVerboseMessage("LAMP_LOGS_DIR environment variable is not set. Will write logs to: \n" + outPath)
+	} else {
+		outPath = xdgStateHome() + sep + "lamp" + sep + "logs" + sep + logFile
+		printVerboseMessage("LAMP_LOGS_DIR environment variable is not set. Will write logs to: \n" + outPath)
 	}
-
-	logConfig := template(outPath, level)
-	logger, err := seelog.LoggerFromConfigAsBytes([]byte(logConfig))
-	if err != nil {
-		fmt.Printf("Error occured while configuring logger: %s\n", err.Error())
-	}
-	log.UseLogger(logger)
-}
-
-func template(outPath string, level string) string {
-	return `
-<seelog type="sync" minlevel="` + strings.ToLower(level) + `">
-	<outputs formatid="main">
-		<rollingfile formatid="main" type="date" filename="` + outPath + `" datepattern="02-01-2006"/>
-	</outputs>
-	<formats>
-		<format id="main" format="%Date(06/01/02 15:04:05.000) [%Level] %Msg%n"/>
-	</formats>
-</seelog>`
+	return outPath
 }
@@ -0,0 +1,167 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// loadTestFiles loads the given YAML files, the last one with the highest precedence, and returns their paths.
+func loadTestFiles(t *testing.T, contents ...string) []string {
+	dir := t.TempDir()
+	configFiles = nil
+	loadErrors = nil
+	t.Cleanup(func() {
+		configFiles = nil
+		activeProfile = ""
+	})
+	var paths []string
+	for i, content := range contents {
+		path := filepath.Join(dir, "lamp"+strconv.Itoa(i)+".yaml")
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		load(path)
+		paths = append(paths, path)
+	}
+	if len(loadErrors) > 0 {
+		t.Fatal(loadErrors[0])
+	}
+	return paths
+}
+
+func TestLookupLayers(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string
+		profile   string
+		key       string
+		want      string
+		wantFound bool
+		wantFile  int
+		wantIn    string
+	}{
+		{
+			name:      "later file overrides earlier file",
+			files:     []string{"user: etc\n", "user: home\n"},
+			key:       "user",
+			want:      "home",
+			wantFound: true,
+			wantFile:  1,
+		},
+		{
+			name:      "key only in earlier file",
+			files:     []string{"user: etc\n", "region: eu\n"},
+			key:       "user",
+			want:      "etc",
+			wantFound: true,
+			wantFile:  0,
+		},
+		{
+			name:      "profile overrides default section of the same file",
+			files:     []string{"user: default\nprofiles:\n  prod:\n    user: prod\n"},
+			profile:   "prod",
+			key:       "user",
+			want:      "prod",
+			wantFound: true,
+			wantFile:  0,
+			wantIn:    "prod",
+		},
+		{
+			name:      "later file overrides profile of earlier file",
+			files:     []string{"profiles:\n  prod:\n    user: etc-prod\n", "user: home\n"},
+			profile:   "prod",
+			key:       "user",
+			want:      "home",
+			wantFound: true,
+			wantFile:  1,
+		},
+		{
+			name:      "profile of later file overrides earlier file",
+			files:     []string{"user: etc\n", "user: home\nprofiles:\n  prod:\n    user: home-prod\n"},
+			profile:   "prod",
+			key:       "user",
+			want:      "home-prod",
+			wantFound: true,
+			wantFile:  1,
+			wantIn:    "prod",
+		},
+		{
+			name:      "empty value clears the key",
+			files:     []string{"proxyHost: proxy.local\n", "proxyHost: \"\"\n"},
+			key:       "proxyHost",
+			want:      "",
+			wantFound: true,
+			wantFile:  1,
+		},
+		{
+			name:      "key without a value clears the key",
+			files:     []string{"proxyHost: proxy.local\n", "proxyHost:\n"},
+			key:       "proxyHost",
+			want:      "",
+			wantFound: true,
+			wantFile:  1,
+		},
+		{
+			name:      "empty profile value clears the default section",
+			files:     []string{"proxyHost: proxy.local\nprofiles:\n  direct:\n    proxyHost: \"\"\n"},
+			profile:   "direct",
+			key:       "proxyHost",
+			want:      "",
+			wantFound: true,
+			wantFile:  0,
+			wantIn:    "direct",
+		},
+		{
+			name:  "not set",
+			files: []string{"user: etc\n"},
+			key:   "region",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			paths := loadTestFiles(t, test.files...)
+			value, layer, found := lookupLayers(test.profile, test.key)
+			if found != test.wantFound {
+				t.Fatalf("lookupLayers() found %t, want %t", found, test.wantFound)
+			}
+			if !found {
+				return
+			}
+			if got := formatValue(value); got != test.want {
+				t.Errorf("lookupLayers() = %q, want %q", got, test.want)
+			}
+			if layer.file.path != paths[test.wantFile] || layer.section != test.wantIn {
+				t.Errorf("lookupLayers() read %s, want %s", layer.source(), paths[test.wantFile]+sectionSuffix(test.wantIn))
+			}
+		})
+	}
+}
+
+func TestGetClearedKey(t *testing.T) {
+	loadTestFiles(t, "requestTimeout: 30\nproxyHost: proxy.local\n", "requestTimeout: \"\"\nproxyHost: \"\"\n")
+	if val := Get("proxyHost"); val != "" {
+		t.Errorf("Get() = %q, want the cleared value", val)
+	}
+	if val := GetInt("requestTimeout", 60); val != 60 {
+		t.Errorf("GetInt() = %d, want the default", val)
+	}
+}
+
+func TestReadConfKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lamp.conf")
+	content := "# OpsGenie\napiKey=key\n## user=commented\nproxyHost=\n\n[default]\nuser=jane\n\n[eu]\n; EU account\napiKey = eu\nregion=eu\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"":        {"apiKey", "proxyHost"},
+		"default": {"user"},
+		"eu":      {"apiKey", "region"},
+	}
+	if got := readConfKeys(path); !reflect.DeepEqual(got, want) {
+		t.Errorf("readConfKeys() = %v, want %v", got, want)
+	}
+}
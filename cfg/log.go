@@ -0,0 +1,183 @@
+package cfg
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cihub/seelog"
+)
+
+const (
+	logFormatText   = "text"
+	logFormatJSON   = "json"
+	logOutputFile   = "file"
+	logOutputStderr = "stderr"
+	logOutputSyslog = "syslog"
+)
+
+var logFormats = []string{logFormatText, logFormatJSON}
+var logOutputs = []string{logOutputFile, logOutputStderr, logOutputSyslog}
+
+// logCommand and requestID are written on every log line, with the active profile, to tell the lines of
+// one execution apart in a shared log.
+var logCommand string
+var requestID = newRequestID()
+
+func init() {
+	seelog.RegisterCustomFormatter("LampContext", func(param string) seelog.FormatterFunc {
+		return formatLogContext
+	})
+	seelog.RegisterCustomFormatter("LampJSON", func(param string) seelog.FormatterFunc {
+		return formatLogJSON
+	})
+	seelog.RegisterReceiver(logOutputStderr, &stderrReceiver{})
+}
+
+// SetLogCommand method sets the name of the command that is written on every log line.
+func SetLogCommand(name string) {
+	logCommand = name
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+// logSettings are the settings of the lamp.log.* keys.
+type logSettings struct {
+	level      string
+	format     string
+	output     string
+	path       string
+	maxSize    int64
+	maxBackups int
+}
+
+// logTemplate returns the seelog configuration of the settings. The file is rotated by size when a maximum
+// size is given and by date otherwise, keeping at most maxBackups rotated files when it is given.
+func logTemplate(s logSettings) string {
+	var receiver string
+	switch s.output {
+	case logOutputStderr, logOutputSyslog:
+		receiver = `<custom name="` + s.output + `" formatid="main"/>`
+	default:
+		rolls := ""
+		if s.maxBackups > 0 {
+			rolls = ` maxrolls="` + strconv.Itoa(s.maxBackups) + `"`
+		}
+		filename := xmlEscape(s.path)
+		if s.maxSize > 0 {
+			receiver = `<rollingfile formatid="main" type="size" filename="` + filename + `" maxsize="` +
+				strconv.FormatInt(s.maxSize, 10) + `"` + rolls + `/>`
+		} else {
+			receiver = `<rollingfile formatid="main" type="date" filename="` + filename + `" datepattern="02-01-2006"` + rolls + `/>`
+		}
+	}
+
+	format := "%Date(06/01/02 15:04:05.000) [%Level] %LampContext %Msg%n"
+	if s.format == logFormatJSON {
+		format = "%LampJSON%n"
+	} else if s.output == logOutputSyslog {
+		// syslog adds the time itself
+		format = "[%Level] %LampContext %Msg"
+	}
+	return `
+<seelog type="sync" minlevel="` + strings.ToLower(s.level) + `">
+	<outputs formatid="main">
+		` + receiver + `
+	</outputs>
+	<formats>
+		<format id="main" format="` + format + `"/>
+	</formats>
+</seelog>`
+}
+
+// xmlEscape escapes the value for an attribute of the seelog configuration, since a log path may hold & or quotes.
+func xmlEscape(val string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(val))
+	return escaped.String()
+}
+
+func formatLogContext(message string, level seelog.LogLevel, context seelog.LogContextInterface) interface{} {
+	fields := "requestId=" + requestID + " profile=" + ActiveProfile()
+	if logCommand != "" {
+		fields = "command=" + strconv.Quote(logCommand) + " " + fields
+	}
+	return fields
+}
+
+type logLine struct {
+	Time      string `json:"time"`
+	Level     string `json:"level"`
+	Command   string `json:"command,omitempty"`
+	RequestID string `json:"requestId"`
+	Profile   string `json:"profile"`
+	Message   string `json:"message"`
+}
+
+func formatLogJSON(message string, level seelog.LogLevel, context seelog.LogContextInterface) interface{} {
+	line := logLine{
+		Time:      context.CallTime().Format(time.RFC3339Nano),
+		Level:     level.String(),
+		Command:   logCommand,
+		RequestID: requestID,
+		Profile:   ActiveProfile(),
+		Message:   message,
+	}
+	content, err := json.Marshal(line)
+	if err != nil {
+		return fmt.Sprintf(`{"level":%q,"message":%q}`, level.String(), message)
+	}
+	return string(content)
+}
+
+// stderrReceiver writes the log lines to the standard error, for containers that collect it.
+type stderrReceiver struct{}
+
+func (r *stderrReceiver) ReceiveMessage(message string, level seelog.LogLevel, context seelog.LogContextInterface) error {
+	_, err := os.Stderr.WriteString(message)
+	return err
+}
+
+func (r *stderrReceiver) AfterParse(initArgs seelog.CustomReceiverInitArgs) error {
+	return nil
+}
+
+func (r *stderrReceiver) Flush() {}
+
+func (r *stderrReceiver) Close() error {
+	return nil
+}
+
+// parseSize parses a size such as 512KB, 10MB or 1GB. A number without a unit is read as bytes.
+func parseSize(val string) (int64, error) {
+	val = strings.ToUpper(strings.TrimSpace(val))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(val, unit.suffix) {
+			val = strings.TrimSpace(strings.TrimSuffix(val, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	size, err := strconv.ParseInt(val, 10, 64)
+	if err != nil || size <= 0 {
+		return 0, errors.New("use a positive size such as 512KB, 10MB or 1GB")
+	}
+	return size * multiplier, nil
+}
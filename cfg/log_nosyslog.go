@@ -0,0 +1,7 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package cfg
+
+// syslog is not available on Windows and Plan 9, lamp.log.output=syslog is refused there.
+const syslogSupported = false
@@ -0,0 +1,54 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package cfg
+
+import (
+	"log/syslog"
+
+	"github.com/cihub/seelog"
+)
+
+const syslogSupported = true
+
+func init() {
+	seelog.RegisterReceiver(logOutputSyslog, &syslogReceiver{})
+}
+
+// syslogReceiver writes the log lines to the local syslog daemon with the lamp tag.
+type syslogReceiver struct {
+	writer *syslog.Writer
+}
+
+func (r *syslogReceiver) ReceiveMessage(message string, level seelog.LogLevel, context seelog.LogContextInterface) error {
+	switch level {
+	case seelog.TraceLvl, seelog.DebugLvl:
+		return r.writer.Debug(message)
+	case seelog.InfoLvl:
+		return r.writer.Info(message)
+	case seelog.WarnLvl:
+		return r.writer.Warning(message)
+	case seelog.ErrorLvl:
+		return r.writer.Err(message)
+	default:
+		return r.writer.Crit(message)
+	}
+}
+
+func (r *syslogReceiver) AfterParse(initArgs seelog.CustomReceiverInitArgs) error {
+	writer, err := syslog.New(syslog.LOG_USER|syslog.LOG_INFO, "lamp")
+	if err != nil {
+		return err
+	}
+	r.writer = writer
+	return nil
+}
+
+func (r *syslogReceiver) Flush() {}
+
+func (r *syslogReceiver) Close() error {
+	if r.writer == nil {
+		return nil
+	}
+	return r.writer.Close()
+}
@@ -0,0 +1,109 @@
+package cfg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cihub/seelog"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		val     string
+		want    int64
+		wantErr bool
+	}{
+		{val: "512", want: 512},
+		{val: "512B", want: 512},
+		{val: "512KB", want: 512 << 10},
+		{val: "10MB", want: 10 << 20},
+		{val: " 10 mb ", want: 10 << 20},
+		{val: "1GB", want: 1 << 30},
+		{val: "", wantErr: true},
+		{val: "0MB", wantErr: true},
+		{val: "-1MB", wantErr: true},
+		{val: "1.5MB", wantErr: true},
+		{val: "10TB", wantErr: true},
+		{val: "MB", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.val, func(t *testing.T) {
+			size, err := parseSize(test.val)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("parseSize(%q) = %d, want an error", test.val, size)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSize(%q) returned %v", test.val, err)
+			}
+			if size != test.want {
+				t.Errorf("parseSize(%q) = %d, want %d", test.val, size, test.want)
+			}
+		})
+	}
+}
+
+func TestLogTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings logSettings
+		contains []string
+	}{
+		{
+			name:     "file rotated by date",
+			settings: logSettings{level: "WARN", format: logFormatText, output: logOutputFile, path: "/var/log/lamp.log", maxBackups: 7},
+			contains: []string{
+				`minlevel="warn"`,
+				`<rollingfile formatid="main" type="date" filename="/var/log/lamp.log" datepattern="02-01-2006" maxrolls="7"/>`,
+				`format="%Date(06/01/02 15:04:05.000) [%Level] %LampContext %Msg%n"`,
+			},
+		},
+		{
+			name: "file rotated by size",
+			settings: logSettings{level: "debug", format: logFormatText, output: logOutputFile, path: "/var/log/lamp.log",
+				maxSize: 10 << 20, maxBackups: 3},
+			contains: []string{
+				`<rollingfile formatid="main" type="size" filename="/var/log/lamp.log" maxsize="10485760" maxrolls="3"/>`,
+			},
+		},
+		{
+			name:     "rotated files kept unless limited",
+			settings: logSettings{level: "info", format: logFormatText, output: logOutputFile, path: `/var/log/R&D "lamp".log`},
+			contains: []string{
+				`<rollingfile formatid="main" type="date" filename="/var/log/R&amp;D &#34;lamp&#34;.log" datepattern="02-01-2006"/>`,
+			},
+		},
+		{
+			name:     "json to stderr",
+			settings: logSettings{level: "info", format: logFormatJSON, output: logOutputStderr},
+			contains: []string{`<custom name="stderr" formatid="main"/>`, `format="%LampJSON%n"`},
+		},
+		{
+			name:     "text to syslog",
+			settings: logSettings{level: "info", format: logFormatText, output: logOutputSyslog},
+			contains: []string{`<custom name="syslog" formatid="main"/>`, `format="[%Level] %LampContext %Msg"`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			template := logTemplate(test.settings)
+			for _, part := range test.contains {
+				if !strings.Contains(template, part) {
+					t.Errorf("logTemplate() = %s, want it to contain %s", template, part)
+				}
+			}
+		})
+	}
+}
+
+func TestLogTemplateIsParsed(t *testing.T) {
+	settings := logSettings{level: "debug", format: logFormatJSON, output: logOutputFile, path: t.TempDir() + `/R&D "lamp".log`,
+		maxSize: 1 << 20, maxBackups: 2}
+	logger, err := seelog.LoggerFromConfigAsBytes([]byte(logTemplate(settings)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Close()
+}
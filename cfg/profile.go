@@ -0,0 +1,114 @@
+package cfg
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	profileEnv     = "LAMP_PROFILE"
+	defaultProfile = "default"
+)
+
+var activeProfile = ""
+
+// SelectProfile method makes the values of the given profile section take precedence over the default section.
+// When name is empty the LAMP_PROFILE environment variable is used; when that is empty too the default section is used.
+func SelectProfile(name string) error {
+	if name == "" {
+		name = os.Getenv(profileEnv)
+	}
+	if name == "" || name == defaultProfile {
+		activeProfile = ""
+		return nil
+	}
+	profiles := ProfileNames()
+	for _, profile := range profiles {
+		if profile == name {
+			printVerboseMessage("Will use the configuration profile: " + name)
+			activeProfile = name
+			return nil
+		}
+	}
+	if len(profiles) == 0 {
+		return errors.New("Profile " + name + " is not defined, the configuration files have no profiles")
+	}
+	return errors.New("Profile " + name + " is not defined. Available profiles: " + strings.Join(profiles, ", "))
+}
+
+// ActiveProfile method returns the name of the selected profile, or "default" when no profile is selected.
+func ActiveProfile() string {
+	if activeProfile == "" {
+		return defaultProfile
+	}
+	return activeProfile
+}
+
+// ProfileNames method returns the sorted names of the profile sections in the loaded configuration files.
+func ProfileNames() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, file := range configFiles {
+		for _, name := range file.profileNames() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f configFile) profileNames() []string {
+	if s, ok := f.source.(*structuredConfig); ok {
+		return s.profileNames()
+	}
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+			continue
+		}
+		if name := strings.TrimSpace(line[1 : len(line)-1]); name != "" && name != defaultProfile {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// GetInProfile method returns the value of the key in the given profile, falling back to the [default] section
+// and then to the keys given before any section.
+func GetInProfile(profile string, key string) string {
+	val, _ := lookupInProfile(profile, key)
+	return val
+}
+
+// lookupInProfile returns the value of the key in the given profile and the path of the file it is read from.
+// See configLayers for the order the files and their sections are looked up in.
+func lookupInProfile(profile string, key string) (string, string) {
+	value, layer, found := lookupLayers(profile, key)
+	if !found {
+		return "", ""
+	}
+	return formatValue(value), layer.file.path
+}
+
+// profileSections returns the sections a key is looked up in for the given profile in each file,
+// in order of precedence.
+func profileSections(profile string) []string {
+	if profile == "" || profile == defaultProfile {
+		return []string{defaultProfile, ""}
+	}
+	return []string{profile, defaultProfile, ""}
+}
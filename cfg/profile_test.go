@@ -0,0 +1,86 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// loadProfileConfig loads a configuration file with the given content and resets the selected profile.
+func loadProfileConfig(t *testing.T, content string) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lamp.conf")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv(logDir, dir)
+	defer os.Unsetenv(logDir)
+	activeProfile = ""
+	LoadConfigFromGivenPath(path)
+}
+
+func TestProfileNames(t *testing.T) {
+	loadProfileConfig(t, "apiKey=default-key\n"+
+		"[default]\nlamp.log.level=info\n"+
+		"[staging]\napiKey=staging-key\n"+
+		"  [ eu-prod ]  \napiKey=eu-key\n"+
+		"[staging]\nopsgenie.api.url=https://api.example.com\n"+
+		"# [commented]\n")
+
+	want := []string{"eu-prod", "staging"}
+	if got := ProfileNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ProfileNames() = %v, want %v", got, want)
+	}
+}
+
+func TestSelectProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		profile string
+		env     string
+		want    string
+		wantErr string
+	}{
+		{name: "nothing selected", content: "[staging]\n", want: "default"},
+		{name: "default selected", content: "[staging]\n", profile: "default", want: "default"},
+		{name: "given profile", content: "[staging]\n[prod]\n", profile: "prod", want: "prod"},
+		{name: "profile from environment", content: "[staging]\n[prod]\n", env: "staging", want: "staging"},
+		{name: "given profile wins over environment", content: "[staging]\n[prod]\n", profile: "prod", env: "staging", want: "prod"},
+		{
+			name:    "unknown profile",
+			content: "[staging]\n[prod]\n",
+			profile: "test",
+			want:    "default",
+			wantErr: "Profile test is not defined. Available profiles: prod, staging",
+		},
+		{
+			name:    "no profiles",
+			content: "apiKey=key\n",
+			profile: "test",
+			want:    "default",
+			wantErr: "Profile test is not defined, the configuration files have no profiles",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loadProfileConfig(t, test.content)
+			os.Setenv(profileEnv, test.env)
+			defer os.Unsetenv(profileEnv)
+
+			err := SelectProfile(test.profile)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("SelectProfile(%q) error = %v, want %q", test.profile, err, test.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("SelectProfile(%q) error = %v", test.profile, err)
+			}
+			if got := ActiveProfile(); got != test.want {
+				t.Errorf("ActiveProfile() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
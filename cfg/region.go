@@ -0,0 +1,72 @@
+package cfg
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+const defaultRegion = "us"
+
+// regionURLs are the OpsGenie API base URLs of the regions that can be given with --region or the region key.
+var regionURLs = map[string]string{
+	"us":      "https://api.opsgenie.com",
+	"eu":      "https://api.eu.opsgenie.com",
+	"sandbox": "https://api.sandbox.opsgenie.com",
+}
+
+// Endpoint is the OpsGenie API base URL requests are sent to, and where it is configured.
+type Endpoint struct {
+	URL    string `json:"url"`
+	Region string `json:"region,omitempty"`
+	Source string `json:"source"`
+}
+
+// Regions method returns the names of the known regions, sorted.
+func Regions() []string {
+	var regions []string
+	for region := range regionURLs {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// RegionURL method returns the API base URL of the region.
+func RegionURL(region string) (string, bool) {
+	url, found := regionURLs[strings.ToLower(strings.TrimSpace(region))]
+	return url, found
+}
+
+// ResolveEndpoint method returns the API base URL to use. An explicit opsgenie.api.url takes precedence,
+// then the given region of the --region flag, then the region key, then the us region. The given region
+// is checked even when opsgenie.api.url takes precedence over it.
+func ResolveEndpoint(region string) (Endpoint, error) {
+	if region != "" {
+		if _, found := RegionURL(region); !found {
+			return Endpoint{}, unknownRegionError(region)
+		}
+	}
+	if setting, found := describeKey("opsgenie.api.url"); found && setting.Value != "" {
+		return Endpoint{URL: setting.Value, Source: "opsgenie.api.url from " + setting.Source}, nil
+	}
+	source := "--region"
+	if region == "" {
+		if setting, found := describeKey("region"); found && setting.Value != "" {
+			region = setting.Value
+			source = "region from " + setting.Source
+		} else {
+			region = defaultRegion
+			source = "default region"
+		}
+	}
+	url, found := RegionURL(region)
+	if !found {
+		return Endpoint{}, unknownRegionError(region)
+	}
+	return Endpoint{URL: url, Region: strings.ToLower(strings.TrimSpace(region)), Source: source}, nil
+}
+
+func unknownRegionError(region string) error {
+	return errors.New("Unknown region " + region + ", use one of " + strings.Join(Regions(), ", "))
+}
@@ -0,0 +1,89 @@
+package cfg
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestResolveEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		region  string
+		want    Endpoint
+		wantErr bool
+	}{
+		{
+			name:    "default region",
+			content: "apiKey: key\n",
+			want:    Endpoint{URL: "https://api.opsgenie.com", Region: "us", Source: "default region"},
+		},
+		{
+			name:    "region key",
+			content: "region: eu\n",
+			want:    Endpoint{URL: "https://api.eu.opsgenie.com", Region: "eu", Source: "region from {file}"},
+		},
+		{
+			name:    "flag overrides region key",
+			content: "region: eu\n",
+			region:  " Sandbox ",
+			want:    Endpoint{URL: "https://api.sandbox.opsgenie.com", Region: "sandbox", Source: "--region"},
+		},
+		{
+			name:    "api url overrides flag and region key",
+			content: "region: eu\nopsgenie:\n  api:\n    url: https://opsgenie.example.com\n",
+			region:  "us",
+			want:    Endpoint{URL: "https://opsgenie.example.com", Source: "opsgenie.api.url from {file}"},
+		},
+		{
+			name:    "environment overrides region key",
+			content: "region: eu\n",
+			env:     map[string]string{"LAMP_REGION": "sandbox"},
+			want:    Endpoint{URL: "https://api.sandbox.opsgenie.com", Region: "sandbox", Source: "region from env LAMP_REGION"},
+		},
+		{
+			name:    "unknown flag",
+			content: "apiKey: key\n",
+			region:  "asia",
+			wantErr: true,
+		},
+		{
+			name:    "unknown flag with api url",
+			content: "opsgenie:\n  api:\n    url: https://opsgenie.example.com\n",
+			region:  "asia",
+			wantErr: true,
+		},
+		{
+			name:    "unknown region key",
+			content: "region: asia\n",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := loadTestConfig(t, "lamp.yaml", test.content)
+			for name, val := range test.env {
+				os.Setenv(name, val)
+				defer os.Unsetenv(name)
+			}
+
+			endpoint, err := ResolveEndpoint(test.region)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("ResolveEndpoint(%q) = %+v, want an error", test.region, endpoint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := test.want
+			want.Source = strings.Replace(want.Source, "{file}", path, 1)
+			if endpoint != want {
+				t.Errorf("ResolveEndpoint(%q) = %+v, want %+v", test.region, endpoint, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package cfg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const envSecretPrefix = "env:"
+
+var warnedReadablePaths = map[string]bool{}
+
+// secretSuffixes are appended to the key of a secret to get the keys of its sources: the value itself,
+// the file holding it and the command printing it.
+var secretSuffixes = []string{"", "File", "Command"}
+
+// resolvedSecrets caches the secrets by profile and key, so that a command is run once per execution.
+var resolvedSecrets = map[string]string{}
+
+// secretSource is a key of a secret that is set, with its value and where it is set.
+type secretSource struct {
+	key   string
+	value string
+	path  string
+}
+
+// GetSecret method returns the value of a secret such as apiKey or proxyPassword. The secret is read from
+// its LAMP_* environment variables, then from the configuration files as described by configLayers. In the first
+// of these that sets it, exactly one of the key itself, the file given by <key>File and the command given by
+// <key>Command must be set. A value in the form env:VAR is read from the environment variable VAR.
+func GetSecret(key string) (string, error) {
+	cacheKey := activeProfile + "/" + key
+	if secret, cached := resolvedSecrets[cacheKey]; cached {
+		return secret, nil
+	}
+	secret, err := readSecret(key)
+	if err != nil {
+		return "", err
+	}
+	resolvedSecrets[cacheKey] = secret
+	return secret, nil
+}
+
+func readSecret(key string) (string, error) {
+	sources, where := findSecretSources(key)
+	if len(sources) == 0 {
+		return "", nil
+	}
+	if len(sources) > 1 {
+		var keys []string
+		for _, source := range sources {
+			keys = append(keys, source.key)
+		}
+		return "", errors.New("Only one of " + key + ", " + key + "File and " + key + "Command can be set, " +
+			strings.Join(keys, " and ") + " are set in " + where)
+	}
+
+	source := sources[0]
+	switch source.key {
+	case key + "File":
+		printVerboseMessage("Will read " + key + " from file: " + source.value)
+		content, err := ioutil.ReadFile(source.value)
+		if err != nil {
+			return "", errors.New("Could not read " + key + "File: " + err.Error())
+		}
+		warnIfReadable(source.value, key)
+		return strings.TrimSpace(string(content)), nil
+	case key + "Command":
+		printVerboseMessage("Will read " + key + " from the output of: " + source.value)
+		return runSecretCommand(key, source.value)
+	}
+	if !strings.HasPrefix(source.value, envSecretPrefix) {
+		warnIfReadable(source.path, key)
+	}
+	return ResolveSecret(source.value)
+}
+
+// findSecretSources returns the keys of the secret that are set in the environment, or else in the layer
+// with the highest precedence that sets any of them, and a description of where they are set.
+func findSecretSources(key string) ([]secretSource, string) {
+	var sources []secretSource
+	for _, suffix := range secretSuffixes {
+		if val, success := getEnv(key + suffix); success {
+			sources = append(sources, secretSource{key: key + suffix, value: val})
+		}
+	}
+	if len(sources) > 0 {
+		return sources, "the environment"
+	}
+	for _, layer := range configLayers(activeProfile) {
+		for _, suffix := range secretSuffixes {
+			if value, found := layer.file.lookup(layer.section, key+suffix); found {
+				sources = append(sources, secretSource{key: key + suffix, value: formatValue(value), path: layer.file.path})
+			}
+		}
+		if len(sources) > 0 {
+			return sources, layer.source()
+		}
+	}
+	return nil, ""
+}
+
+// ResolveSecret method returns the value of the environment variable VAR for a value in the form env:VAR,
+// and the value itself otherwise.
+func ResolveSecret(val string) (string, error) {
+	if !strings.HasPrefix(val, envSecretPrefix) {
+		return val, nil
+	}
+	name := strings.TrimPrefix(val, envSecretPrefix)
+	secret := os.Getenv(name)
+	if secret == "" {
+		return "", errors.New("Environment variable " + name + " is not set")
+	}
+	return secret, nil
+}
+
+func runSecretCommand(key string, command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var out bytes.Buffer
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.New("Could not run " + key + "Command: " + err.Error())
+	}
+	secret := strings.TrimSpace(out.String())
+	if secret == "" {
+		return "", errors.New(key + "Command printed nothing")
+	}
+	return secret, nil
+}
+
+// warnIfReadable warns once per file when a file holding a secret can be read by the group or by other users.
+func warnIfReadable(path string, key string) {
+	if path == "" || runtime.GOOS == "windows" || warnedReadablePaths[path] {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm()&0044 == 0 {
+		return
+	}
+	warnedReadablePaths[path] = true
+	fmt.Fprintf(os.Stderr, "WARNING: %s holds %s and can be read by other users, restrict it with: chmod 600 %s\n",
+		path, key, path)
+}
@@ -0,0 +1,121 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadTestConfig writes the content to a configuration file with the given name and loads it.
+func loadTestConfig(t *testing.T, name string, content string) string {
+	dir := t.TempDir()
+	t.Cleanup(func() {
+		configFiles = nil
+		activeProfile = ""
+	})
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	LoadConfigFromGivenPath(path)
+	if len(loadErrors) > 0 {
+		t.Fatal(loadErrors[0])
+	}
+	return path
+}
+
+func TestGetSecret(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "api_key")
+	if err := ioutil.WriteFile(keyFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("TEST_LAMP_API_KEY", "from-var")
+	defer os.Unsetenv("TEST_LAMP_API_KEY")
+
+	tests := []struct {
+		name    string
+		content string
+		profile string
+		env     map[string]string
+		want    string
+		wantErr string
+	}{
+		{name: "key", content: "apiKey: from-key\n", want: "from-key"},
+		{name: "env form", content: "apiKey: env:TEST_LAMP_API_KEY\n", want: "from-var"},
+		{name: "file", content: "apiKeyFile: " + keyFile + "\n", want: "from-file"},
+		{name: "command", content: "apiKeyCommand: echo from-command\n", want: "from-command"},
+		{name: "not set", content: "user: jane@example.com\n", want: ""},
+		{
+			name:    "profile file overrides default key",
+			content: "apiKey: from-key\nprofiles:\n  prod:\n    apiKeyFile: " + keyFile + "\n",
+			profile: "prod",
+			want:    "from-file",
+		},
+		{
+			name:    "default key is used without the profile",
+			content: "apiKey: from-key\nprofiles:\n  prod:\n    apiKeyFile: " + keyFile + "\n",
+			want:    "from-key",
+		},
+		{
+			name:    "more than one source in a section",
+			content: "apiKey: from-key\napiKeyCommand: echo from-command\n",
+			wantErr: "apiKey and apiKeyCommand are set",
+		},
+		{
+			name:    "environment overrides the files",
+			content: "apiKey: from-key\napiKeyCommand: echo from-command\n",
+			env:     map[string]string{"LAMP_API_KEY_COMMAND": "echo from-env-command"},
+			want:    "from-env-command",
+		},
+		{
+			name:    "more than one source in the environment",
+			content: "user: jane@example.com\n",
+			env:     map[string]string{"LAMP_API_KEY": "from-env", "LAMP_API_KEY_FILE": keyFile},
+			wantErr: "apiKey and apiKeyFile are set in the environment",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loadTestConfig(t, "lamp.yaml", test.content)
+			activeProfile = test.profile
+			for name, val := range test.env {
+				os.Setenv(name, val)
+				defer os.Unsetenv(name)
+			}
+
+			secret, err := GetSecret("apiKey")
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("GetSecret() returned %v, want an error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if secret != test.want {
+				t.Errorf("GetSecret() = %q, want %q", secret, test.want)
+			}
+		})
+	}
+}
+
+func TestGetSecretRunsCommandOnce(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "runs")
+	loadTestConfig(t, "lamp.yaml", "apiKeyCommand: echo run >> "+counter+" && echo from-command\n")
+
+	for i := 0; i < 3; i++ {
+		if secret, err := GetSecret("apiKey"); err != nil || secret != "from-command" {
+			t.Fatalf("GetSecret() = %q, %v, want from-command", secret, err)
+		}
+	}
+	content, err := ioutil.ReadFile(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs := strings.Count(string(content), "run"); runs != 1 {
+		t.Errorf("apiKeyCommand ran %d times, want 1", runs)
+	}
+}
@@ -0,0 +1,278 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// profilesKey is the top level key of YAML and TOML configuration files that holds the profile sections.
+const profilesKey = "profiles"
+
+// configSource is implemented by the parsers of the supported configuration file formats.
+// Section "" holds the keys given outside of any profile.
+type configSource interface {
+	Get(section string, key string) string
+}
+
+// structuredConfig holds a YAML or TOML configuration file. Nested maps are flattened into dotted keys,
+// so "opsgenie: {api: {url: ...}}" is read with the key "opsgenie.api.url" just like the flat conf format.
+// The maps and lists themselves are kept under their own keys for the typed accessors.
+type structuredConfig struct {
+	sections map[string]map[string]interface{}
+}
+
+func isStructuredConfigPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".toml":
+		return true
+	}
+	return false
+}
+
+func readStructuredConfig(path string) (*structuredConfig, error) {
+	var data interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		values := map[string]interface{}{}
+		if _, err := toml.DecodeFile(path, &values); err != nil {
+			return nil, err
+		}
+		data = values
+	default:
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		values := map[interface{}]interface{}{}
+		if err := yaml.Unmarshal(content, &values); err != nil {
+			return nil, err
+		}
+		data = values
+	}
+	return newStructuredConfig(normalizeValue(data).(map[string]interface{})), nil
+}
+
+func newStructuredConfig(data map[string]interface{}) *structuredConfig {
+	s := &structuredConfig{sections: map[string]map[string]interface{}{"": {}}}
+	for key, value := range data {
+		if key != profilesKey {
+			continue
+		}
+		profiles, _ := value.(map[string]interface{})
+		for name, profileValue := range profiles {
+			section := map[string]interface{}{}
+			if values, ok := profileValue.(map[string]interface{}); ok {
+				flatten("", values, section)
+			}
+			s.sections[name] = section
+		}
+	}
+	delete(data, profilesKey)
+	flatten("", data, s.sections[""])
+	return s
+}
+
+// flatten stores every value of the map under its dotted path, descending into nested maps.
+func flatten(prefix string, values map[string]interface{}, out map[string]interface{}) {
+	for key, value := range values {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		out[path] = value
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(path, nested, out)
+		}
+	}
+}
+
+// normalizeValue converts the maps produced by the YAML and TOML decoders to map[string]interface{}
+// and their lists to []interface{}.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for key, item := range v {
+			m[fmt.Sprint(key)] = normalizeValue(item)
+		}
+		return m
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for key, item := range v {
+			m[key] = normalizeValue(item)
+		}
+		return m
+	case []map[string]interface{}:
+		var list []interface{}
+		for _, item := range v {
+			list = append(list, normalizeValue(item))
+		}
+		return list
+	case []interface{}:
+		var list []interface{}
+		for _, item := range v {
+			list = append(list, normalizeValue(item))
+		}
+		return list
+	}
+	return value
+}
+
+// lookup returns the value of the key in the section and whether the key is set. A key given without
+// a value is set to an empty string.
+func (s *structuredConfig) lookup(section string, key string) (interface{}, bool) {
+	value, ok := s.sections[section][key]
+	if ok && value == nil {
+		value = ""
+	}
+	return value, ok
+}
+
+// Get returns the value of the key as a string. Lists of scalars are joined with commas, maps are returned empty.
+func (s *structuredConfig) Get(section string, key string) string {
+	value, ok := s.lookup(section, key)
+	if !ok {
+		return ""
+	}
+	return formatValue(value)
+}
+
+func (s *structuredConfig) profileNames() []string {
+	var names []string
+	for name := range s.sections {
+		if name != "" && name != defaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case map[string]interface{}:
+		return ""
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			if _, nested := item.(map[string]interface{}); nested {
+				return ""
+			}
+			items = append(items, formatValue(item))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// Lookup method returns the raw value of the key in the selected profile, falling back to the default section,
+// looked up in the order described by configLayers. A LAMP_* environment variable of the key takes precedence and is returned as a string.
+// Values of YAML and TOML files keep their types, including nested maps and lists; values of conf files are strings.
+func Lookup(key string) (interface{}, bool) {
+	if val, success := getEnv(key); success {
+		return val, true
+	}
+	value, _, found := lookupLayers(activeProfile, key)
+	return value, found
+}
+
+// GetInt method returns the value of the key as an int, or def when it is not set or is not a number.
+func GetInt(key string, def int) int {
+	value, ok := Lookup(key)
+	if !ok {
+		return def
+	}
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	i, err := strconv.Atoi(strings.TrimSpace(formatValue(value)))
+	if err != nil {
+		return def
+	}
+	return i
+}
+
+// GetBool method returns the value of the key as a bool, or def when it is not set or is not a boolean.
+func GetBool(key string, def bool) bool {
+	value, ok := Lookup(key)
+	if !ok {
+		return def
+	}
+	if b, isBool := value.(bool); isBool {
+		return b
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(formatValue(value)))
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// GetDuration method returns the value of the key as a duration, or def when it is not set or cannot be parsed.
+// Numbers without a unit are read as seconds.
+func GetDuration(key string, def time.Duration) time.Duration {
+	value, ok := Lookup(key)
+	if !ok {
+		return def
+	}
+	duration, err := ParseDuration(formatValue(value))
+	if err != nil {
+		return def
+	}
+	return duration
+}
+
+// GetStringSlice method returns the items of a list value. Values of conf files are split at commas.
+func GetStringSlice(key string) []string {
+	value, ok := Lookup(key)
+	if !ok {
+		return nil
+	}
+	var items []string
+	if list, isList := value.([]interface{}); isList {
+		for _, item := range list {
+			items = append(items, formatValue(item))
+		}
+		return items
+	}
+	for _, item := range strings.Split(formatValue(value), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Unmarshal method decodes the value of the key, such as a nested map or a list of maps, into out.
+// out is decoded with the yaml field tags of its type.
+func Unmarshal(key string, out interface{}) error {
+	value, ok := Lookup(key)
+	if !ok {
+		return errors.New("Configuration key " + key + " is not set")
+	}
+	content, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(content, out)
+}
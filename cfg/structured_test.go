@@ -0,0 +1,281 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeStructuredConfig writes a configuration file with the given name and content to a temporary directory.
+func writeStructuredConfig(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewStructuredConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want map[string]map[string]interface{}
+	}{
+		{
+			name: "flat keys",
+			data: map[string]interface{}{"apiKey": "key", "lamp.log.level": "info"},
+			want: map[string]map[string]interface{}{
+				"": {"apiKey": "key", "lamp.log.level": "info"},
+			},
+		},
+		{
+			name: "nested maps are flattened into dotted keys",
+			data: map[string]interface{}{
+				"opsgenie": map[string]interface{}{"api": map[string]interface{}{"url": "https://api.eu.opsgenie.com"}},
+			},
+			want: map[string]map[string]interface{}{
+				"": {
+					"opsgenie":         map[string]interface{}{"api": map[string]interface{}{"url": "https://api.eu.opsgenie.com"}},
+					"opsgenie.api":     map[string]interface{}{"url": "https://api.eu.opsgenie.com"},
+					"opsgenie.api.url": "https://api.eu.opsgenie.com",
+				},
+			},
+		},
+		{
+			name: "lists and null values are kept",
+			data: map[string]interface{}{
+				"tags":  []interface{}{"a", "b"},
+				"proxy": map[string]interface{}{"host": nil},
+			},
+			want: map[string]map[string]interface{}{
+				"": {
+					"tags":       []interface{}{"a", "b"},
+					"proxy":      map[string]interface{}{"host": nil},
+					"proxy.host": nil,
+				},
+			},
+		},
+		{
+			name: "profiles become sections",
+			data: map[string]interface{}{
+				"apiKey": "key",
+				"profiles": map[string]interface{}{
+					"default": map[string]interface{}{"lamp": map[string]interface{}{"log": map[string]interface{}{"level": "warn"}}},
+					"staging": map[string]interface{}{"apiKey": "staging-key"},
+					"empty":   nil,
+				},
+			},
+			want: map[string]map[string]interface{}{
+				"": {"apiKey": "key"},
+				"default": {
+					"lamp":           map[string]interface{}{"log": map[string]interface{}{"level": "warn"}},
+					"lamp.log":       map[string]interface{}{"level": "warn"},
+					"lamp.log.level": "warn",
+				},
+				"staging": {"apiKey": "staging-key"},
+				"empty":   {},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := newStructuredConfig(test.data).sections; !reflect.DeepEqual(got, test.want) {
+				t.Errorf("newStructuredConfig() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{name: "scalar", value: 5, want: 5},
+		{
+			name:  "yaml map with non string keys",
+			value: map[interface{}]interface{}{1: "one", "two": map[interface{}]interface{}{true: "yes"}},
+			want:  map[string]interface{}{"1": "one", "two": map[string]interface{}{"true": "yes"}},
+		},
+		{
+			name:  "list of yaml maps",
+			value: []interface{}{map[interface{}]interface{}{"name": "a"}, "b"},
+			want:  []interface{}{map[string]interface{}{"name": "a"}, "b"},
+		},
+		{
+			name:  "toml array of tables",
+			value: []map[string]interface{}{{"host": "a", "port": int64(1)}, {"host": "b"}},
+			want:  []interface{}{map[string]interface{}{"host": "a", "port": int64(1)}, map[string]interface{}{"host": "b"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := normalizeValue(test.value); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("normalizeValue() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadStructuredConfig(t *testing.T) {
+	files := map[string]string{
+		"lamp.yaml": "apiKey: key\n" +
+			"opsgenie:\n  api:\n    url: https://api.eu.opsgenie.com\n" +
+			"hosts:\n  - name: a\n    port: 1\n  - name: b\n    port: 2\n" +
+			"tags: [x, z]\n" +
+			"profiles:\n  staging:\n    apiKey: staging-key\n",
+		"lamp.toml": "apiKey = \"key\"\n" +
+			"tags = [\"x\", \"z\"]\n" +
+			"[opsgenie.api]\nurl = \"https://api.eu.opsgenie.com\"\n" +
+			"[[hosts]]\nname = \"a\"\nport = 1\n" +
+			"[[hosts]]\nname = \"b\"\nport = 2\n" +
+			"[profiles.staging]\napiKey = \"staging-key\"\n",
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			s, err := readStructuredConfig(writeStructuredConfig(t, name, content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			gets := []struct{ section, key, want string }{
+				{"", "apiKey", "key"},
+				{"", "opsgenie.api.url", "https://api.eu.opsgenie.com"},
+				{"", "opsgenie", ""},
+				{"", "tags", "x,z"},
+				{"", "hosts", ""},
+				{"", "missing", ""},
+				{"staging", "apiKey", "staging-key"},
+				{"staging", "opsgenie.api.url", ""},
+			}
+			for _, get := range gets {
+				if got := s.Get(get.section, get.key); got != get.want {
+					t.Errorf("Get(%q, %q) = %q, want %q", get.section, get.key, got, get.want)
+				}
+			}
+			hosts, _ := s.lookup("", "hosts")
+			list, ok := hosts.([]interface{})
+			if !ok || len(list) != 2 {
+				t.Fatalf("hosts = %#v, want a list of two tables", hosts)
+			}
+			if host, ok := list[1].(map[string]interface{}); !ok || host["name"] != "b" {
+				t.Errorf("hosts[1] = %#v, want the table named b", list[1])
+			}
+			if got := s.profileNames(); !reflect.DeepEqual(got, []string{"staging"}) {
+				t.Errorf("profileNames() = %v, want [staging]", got)
+			}
+		})
+	}
+}
+
+func TestTypedAccessors(t *testing.T) {
+	os.Setenv(logDir, t.TempDir())
+	defer os.Unsetenv(logDir)
+	LoadConfigFromGivenPath(writeStructuredConfig(t, "lamp.yaml", ""+
+		"retries: 3\n"+
+		"ratio: 2.5\n"+
+		"quoted: \"7\"\n"+
+		"verbose: true\n"+
+		"flag: \"yes\"\n"+
+		"timeout: 90s\n"+
+		"delay: 30\n"+
+		"empty:\n"+
+		"teams: [ops, dev]\n"+
+		"csv: \"a, b,,c\"\n"+
+		"hosts:\n  - name: a\n    port: 1\n  - name: b\n    port: 2\n"+
+		"profiles:\n  staging:\n    retries: 5\n    teams: [qa]\n"))
+	activeProfile = ""
+	defer func() { activeProfile = "" }()
+
+	ints := []struct {
+		key  string
+		want int
+	}{
+		{"retries", 3},
+		{"ratio", 2},
+		{"quoted", 7},
+		{"verbose", -1},
+		{"empty", -1},
+		{"missing", -1},
+	}
+	for _, test := range ints {
+		if got := GetInt(test.key, -1); got != test.want {
+			t.Errorf("GetInt(%q) = %d, want %d", test.key, got, test.want)
+		}
+	}
+
+	bools := []struct {
+		key       string
+		def, want bool
+	}{
+		{"verbose", false, true},
+		{"retries", true, true},
+		{"flag", true, true},
+		{"missing", true, true},
+		{"missing", false, false},
+	}
+	for _, test := range bools {
+		if got := GetBool(test.key, test.def); got != test.want {
+			t.Errorf("GetBool(%q, %t) = %t, want %t", test.key, test.def, got, test.want)
+		}
+	}
+
+	durations := []struct {
+		key  string
+		want time.Duration
+	}{
+		{"timeout", 90 * time.Second},
+		{"delay", 30 * time.Second},
+		{"ratio", 2500 * time.Millisecond},
+		{"teams", time.Minute},
+		{"missing", time.Minute},
+	}
+	for _, test := range durations {
+		if got := GetDuration(test.key, time.Minute); got != test.want {
+			t.Errorf("GetDuration(%q) = %s, want %s", test.key, got, test.want)
+		}
+	}
+
+	slices := []struct {
+		key  string
+		want []string
+	}{
+		{"teams", []string{"ops", "dev"}},
+		{"csv", []string{"a", "b", "c"}},
+		{"retries", []string{"3"}},
+		{"missing", nil},
+	}
+	for _, test := range slices {
+		if got := GetStringSlice(test.key); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("GetStringSlice(%q) = %v, want %v", test.key, got, test.want)
+		}
+	}
+
+	var hosts []struct {
+		Name string `yaml:"name"`
+		Port int    `yaml:"port"`
+	}
+	if err := Unmarshal("hosts", &hosts); err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 || hosts[0].Name != "a" || hosts[1].Port != 2 {
+		t.Errorf("Unmarshal(hosts) = %+v", hosts)
+	}
+	if err := Unmarshal("missing", &hosts); err == nil {
+		t.Error("Unmarshal(missing) returned no error")
+	}
+
+	activeProfile = "staging"
+	if got := GetInt("retries", -1); got != 5 {
+		t.Errorf("GetInt(retries) in staging = %d, want 5", got)
+	}
+	if got := GetStringSlice("teams"); !reflect.DeepEqual(got, []string{"qa"}) {
+		t.Errorf("GetStringSlice(teams) in staging = %v, want [qa]", got)
+	}
+	if got := GetDuration("timeout", time.Minute); got != 90*time.Second {
+		t.Errorf("GetDuration(timeout) in staging = %s, want the default section value 1m30s", got)
+	}
+}
@@ -0,0 +1,234 @@
+package cfg
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reservedEnvNames are the LAMP_* environment variables that do not override a configuration key.
+var reservedEnvNames = map[string]bool{
+	confPath:   true,
+	logDir:     true,
+	stateDir:   true,
+	profileEnv: true,
+}
+
+var logLevels = []string{"trace", "debug", "info", "warn", "error", "critical", "off"}
+
+// valueValidators check the values of the known keys that are not free text.
+var valueValidators = map[string]func(string) error{
+	"opsgenie.api.url":    validateURL,
+	"region":              validateOneOf(Regions()...),
+	"proxyPort":           validatePort,
+	"proxyProtocol":       validateOneOf("http", "https", "socks5"),
+	"connectionTimeout":   validateDuration,
+	"requestTimeout":      validateDuration,
+	"lamp.log.level":      validateOneOf(logLevels...),
+	"lamp.log.format":     validateOneOf(logFormats...),
+	"lamp.log.output":     validateOneOf(logOutputs...),
+	"lamp.log.maxSize":    validateSize,
+	"lamp.log.maxBackups": validateCount,
+}
+
+// Validate method checks the configuration files that are read and the LAMP_* environment variables.
+// It returns a problem for every file that can not be read, every value that has the wrong type or is out
+// of range, and every key that is not known to lamp, suggesting the known key it may be a typo of.
+// Unknown LAMP_* environment variables are returned as warnings, since they may be meant for another tool.
+func Validate() (problems []error, warnings []error) {
+	problems = append(problems, loadErrors...)
+	known := map[string]bool{}
+	for _, key := range knownKeys {
+		known[key] = true
+	}
+
+	for _, file := range configFiles {
+		for _, section := range append([]string{"", defaultProfile}, file.profileNames()...) {
+			where := " in " + file.path + sectionSuffix(section)
+			for _, key := range file.keys(section) {
+				if !known[key] {
+					problems = append(problems, errors.New("Unknown key "+key+where+suggest(key, knownKeys)))
+					continue
+				}
+				if err := validateValue(key, file.source.Get(section, key)); err != nil {
+					problems = append(problems, errors.New(err.Error()+where))
+				}
+			}
+		}
+	}
+
+	envKeys := map[string]string{}
+	var envNames []string
+	for _, key := range knownKeys {
+		envKeys[EnvName(key)] = key
+		envNames = append(envNames, EnvName(key))
+	}
+	var environ []string
+	for _, entry := range os.Environ() {
+		if strings.HasPrefix(entry, envPrefix) {
+			environ = append(environ, entry)
+		}
+	}
+	sort.Strings(environ)
+	for _, entry := range environ {
+		parts := strings.SplitN(entry, "=", 2)
+		name, val := parts[0], parts[1]
+		if reservedEnvNames[name] || val == "" {
+			continue
+		}
+		key, found := envKeys[name]
+		if !found {
+			warnings = append(warnings, errors.New("Unknown environment variable "+name+suggest(name, envNames)))
+			continue
+		}
+		if err := validateValue(key, val); err != nil {
+			problems = append(problems, errors.New(err.Error()+" in environment variable "+name))
+		}
+	}
+	return problems, warnings
+}
+
+func validateValue(key string, val string) error {
+	validator, found := valueValidators[key]
+	if !found || val == "" {
+		// an empty value clears the key of the files read before
+		return nil
+	}
+	if err := validator(strings.TrimSpace(val)); err != nil {
+		return errors.New("Invalid " + key + " \"" + val + "\": " + err.Error())
+	}
+	return nil
+}
+
+func validateURL(val string) error {
+	u, err := url.Parse(val)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("the URL must start with https:// or http://")
+	}
+	if u.Host == "" {
+		return errors.New("the URL has no host")
+	}
+	return nil
+}
+
+func validatePort(val string) error {
+	port, err := strconv.Atoi(val)
+	if err != nil {
+		return errors.New("the port must be a number")
+	}
+	if port < 1 || port > 65535 {
+		return errors.New("the port must be between 1 and 65535")
+	}
+	return nil
+}
+
+func validateSize(val string) error {
+	_, err := parseSize(val)
+	return err
+}
+
+// validateCount accepts a positive number, seelog keeps every rotated file when maxrolls is 0.
+func validateCount(val string) error {
+	count, err := strconv.Atoi(val)
+	if err != nil || count <= 0 {
+		return errors.New("use a positive number")
+	}
+	return nil
+}
+
+func validateDuration(val string) error {
+	duration, err := ParseDuration(val)
+	if err != nil {
+		return err
+	}
+	if duration <= 0 {
+		return errors.New("the duration must be positive")
+	}
+	return nil
+}
+
+func validateOneOf(values ...string) func(string) error {
+	return func(val string) error {
+		for _, v := range values {
+			if strings.EqualFold(v, val) {
+				return nil
+			}
+		}
+		return errors.New("use one of " + strings.Join(values, ", "))
+	}
+}
+
+// ParseDuration method parses a duration such as 30s or 2m. A number without a unit is read as seconds.
+func ParseDuration(val string) (time.Duration, error) {
+	val = strings.TrimSpace(val)
+	if seconds, err := strconv.ParseFloat(val, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	duration, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, errors.New("use a number of seconds or a duration such as 30s or 2m")
+	}
+	return duration, nil
+}
+
+// suggest returns a "did you mean" hint with the candidate closest to the name, or "" when none is close.
+func suggest(name string, candidates []string) string {
+	best := ""
+	bestDistance := len(name)/3 + 1
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if distance <= bestDistance && (best == "" || distance < editDistance(strings.ToLower(name), strings.ToLower(best))) {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return ", did you mean " + best + "?"
+}
+
+// editDistance returns the Levenshtein distance of the strings.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func sectionSuffix(section string) string {
+	if section == "" {
+		return ""
+	}
+	return " [" + section + "]"
+}
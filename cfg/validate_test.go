@@ -0,0 +1,159 @@
+package cfg
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		val     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{val: "30", want: 30 * time.Second},
+		{val: " 1.5 ", want: 1500 * time.Millisecond},
+		{val: "30s", want: 30 * time.Second},
+		{val: "2m", want: 2 * time.Minute},
+		{val: "1h30m", want: 90 * time.Minute},
+		{val: "", wantErr: true},
+		{val: "30 seconds", wantErr: true},
+		{val: "fast", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.val, func(t *testing.T) {
+			duration, err := ParseDuration(test.val)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("ParseDuration(%q) = %s, want an error", test.val, duration)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDuration(%q) returned %v", test.val, err)
+			}
+			if duration != test.want {
+				t.Errorf("ParseDuration(%q) = %s, want %s", test.val, duration, test.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "apiKey", b: "apiKey", want: 0},
+		{a: "", b: "region", want: 6},
+		{a: "apikey", b: "apiKey", want: 1},
+		{a: "proxyhost", b: "proxyHost", want: 1},
+		{a: "regoin", b: "region", want: 2},
+		{a: "kitten", b: "sitting", want: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.a+"/"+test.b, func(t *testing.T) {
+			if got := editDistance(test.a, test.b); got != test.want {
+				t.Errorf("editDistance(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "apikey", want: ", did you mean apiKey?"},
+		{name: "proxyHots", want: ", did you mean proxyHost?"},
+		{name: "lamp.log.levle", want: ", did you mean lamp.log.level?"},
+		{name: "regoin", want: ", did you mean region?"},
+		{name: "LAMP_APIKEY", want: ", did you mean LAMP_API_KEY?"},
+		{name: "colour", want: ""},
+	}
+	candidates := append([]string{"LAMP_API_KEY"}, knownKeys...)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := suggest(test.name, candidates); got != test.want {
+				t.Errorf("suggest(%q) = %q, want %q", test.name, got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		env      map[string]string
+		problems []string
+		warnings []string
+	}{
+		{
+			name:    "valid",
+			content: "apiKey: key\nregion: eu\nrequestTimeout: 30s\nproxyPort: 8080\nlamp:\n  log:\n    level: debug\n    maxSize: 10MB\n",
+		},
+		{
+			name:     "unknown key",
+			content:  "apikey: key\n",
+			problems: []string{"Unknown key apikey in "},
+		},
+		{
+			name:     "invalid values",
+			content:  "region: asia\nproxyPort: 70000\nprofiles:\n  prod:\n    requestTimeout: fast\n",
+			problems: []string{"Invalid proxyPort \"70000\"", "Invalid region \"asia\"", "Invalid requestTimeout \"fast\""},
+		},
+		{
+			name:     "no rotated log files kept",
+			content:  "apiKey: key\nlamp:\n  log:\n    maxBackups: 0\n",
+			problems: []string{"Invalid lamp.log.maxBackups \"0\""},
+		},
+		{
+			name:    "empty value clears the key",
+			content: "region: \"\"\n",
+		},
+		{
+			name:     "invalid environment variable",
+			content:  "apiKey: key\n",
+			env:      map[string]string{"LAMP_LOG_LEVEL": "loud"},
+			problems: []string{"in environment variable LAMP_LOG_LEVEL"},
+		},
+		{
+			name:     "unknown environment variable",
+			content:  "apiKey: key\n",
+			env:      map[string]string{"LAMP_APIKEY": "key", "LAMP_PROFILE": "prod"},
+			warnings: []string{"Unknown environment variable LAMP_APIKEY, did you mean LAMP_API_KEY?"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loadTestConfig(t, "lamp.yaml", test.content)
+			for name, val := range test.env {
+				os.Setenv(name, val)
+				defer os.Unsetenv(name)
+			}
+
+			problems, warnings := Validate()
+			checkErrors(t, "problems", problems, test.problems)
+			checkErrors(t, "warnings", warnings, test.warnings)
+		})
+	}
+}
+
+// checkErrors checks that every error contains the wanted part at the same index.
+func checkErrors(t *testing.T, name string, errs []error, want []string) {
+	if len(errs) != len(want) {
+		t.Errorf("%s = %v, want %d containing %q", name, errs, len(want), want)
+		return
+	}
+	for i, err := range errs {
+		if !strings.Contains(err.Error(), want[i]) {
+			t.Errorf("%s[%d] = %q, want it to contain %q", name, i, err.Error(), want[i])
+		}
+	}
+}
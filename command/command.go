@@ -8,17 +8,26 @@ Package command creates various OpsGenie API clients:
  	- Heartbeat
  	- Integration
  	- Policy
+ 	- User
+ 	- Contact
+ 	- Notification
+ 	- Team
+ 	- Schedule
+ 	- Escalation
 And contains command action implementations that uses OpsGenie API clients mentioned above. Commands use OpsGenie Go SDK to send requests to OpsGenie.
 */
 package command
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	gcli "github.com/codegangsta/cli"
@@ -46,13 +55,23 @@ func printWarningMessage(message string) {
 /*
 The 'API Key' is the most common parameter for all commands.
 It is provided either on command line or on the configuration file.
+A value in the form env:VAR is read from the environment variable VAR.
 */
 func grabAPIKey(c *gcli.Context) string {
 	if val, success := getVal("apiKey", c); success {
-		return val
+		apiKey, err := cfg.ResolveSecret(val)
+		if err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
+		}
+		return apiKey
+	}
+	printVerboseMessage("apiKey flag is not set in the command, reading apiKey from LAMP_API_KEY or config..")
+	apiKey, err := cfg.GetSecret("apiKey")
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
 	}
-	apiKey := cfg.Get("apiKey")
-	printVerboseMessage("apiKey flag is not set in the command, reading apiKey from config..")
 	return apiKey
 }
 
@@ -98,7 +117,11 @@ func proxyConf(host string, port int) (proxy *ogcli.ProxyConfiguration) {
 	pc.Host = host
 	pc.Port = port
 	username := cfg.Get("proxyUsername")
-	password := cfg.Get("proxyPassword")
+	password, err := cfg.GetSecret("proxyPassword")
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
 	if username != "" && password != "" {
 		pc.Username = username
 		pc.Password = password
@@ -124,18 +147,12 @@ func parseDuration(key string) time.Duration {
 	strDuration := cfg.Get(key)
 	if strDuration != "" {
 		printVerboseMessage("Will try to parse [" + key + "] with value [" + strDuration + "] from string to time duration in seconds..")
-		var reqTimeout time.Duration
-		var err error
-		if strings.HasSuffix(strDuration, "s") {
-			reqTimeout, err = time.ParseDuration(strDuration)
-		} else {
-			reqTimeout, err = time.ParseDuration(strDuration + "s")
-		}
+		duration, err := cfg.ParseDuration(strDuration)
 		if err != nil {
-			printVerboseMessage("Could not parse " + strDuration + " from string to time duration, opsgenie client will use default value.")
+			fmt.Fprintf(os.Stderr, "WARNING: Invalid %s \"%s\": %s, the default value will be used\n", key, strDuration, err.Error())
 			return 0
 		}
-		return reqTimeout
+		return duration
 	}
 	printVerboseMessage("Could not parse [" + key + "] with value [" + strDuration + "].")
 	return 0
@@ -150,9 +167,7 @@ func initialize(c *gcli.Context) *ogcli.OpsGenieClient {
 	apiKey := grabAPIKey(c)
 	cli := new(ogcli.OpsGenieClient)
 	cli.SetAPIKey(apiKey)
-	if apiURL := cfg.Get("opsgenie.api.url"); apiURL != "" {
-		cli.SetOpsGenieAPIUrl(apiURL)
-	}
+	cli.SetOpsGenieAPIUrl(resolveEndpoint(c).URL)
 	proxyHost := cfg.Get("proxyHost")
 	proxyPort, err := strconv.Atoi(cfg.Get("proxyPort"))
 	if err == nil && proxyPort != 0 && proxyHost != "" {
@@ -247,6 +262,76 @@ func NewUserClient(c *gcli.Context) (*ogcli.OpsGenieUserV2Client, error) {
 	return userCli, nil
 }
 
+// NewContactClient instantiates a new OpsGenieContactClient.
+func NewContactClient(c *gcli.Context) (*ogcli.OpsGenieContactClient, error) {
+	cli := initialize(c)
+	contactCli, cliErr := cli.Contact()
+
+	if cliErr != nil {
+		message := "Can not create the contact client. " + cliErr.Error()
+		fmt.Printf("%s\n", message)
+		return nil, errors.New(message)
+	}
+	printVerboseMessage("Contact Client created..")
+	return contactCli, nil
+}
+
+// NewNotificationClient instantiates a new OpsGenieNotificationV2Client.
+func NewNotificationClient(c *gcli.Context) (*ogcli.OpsGenieNotificationV2Client, error) {
+	cli := initialize(c)
+	notificationCli, cliErr := cli.NotificationV2()
+
+	if cliErr != nil {
+		message := "Can not create the notification client. " + cliErr.Error()
+		fmt.Printf("%s\n", message)
+		return nil, errors.New(message)
+	}
+	printVerboseMessage("Notification Client created..")
+	return notificationCli, nil
+}
+
+// NewTeamClient instantiates a new OpsGenieTeamClient.
+func NewTeamClient(c *gcli.Context) (*ogcli.OpsGenieTeamClient, error) {
+	cli := initialize(c)
+	teamCli, cliErr := cli.Team()
+
+	if cliErr != nil {
+		message := "Can not create the team client. " + cliErr.Error()
+		fmt.Printf("%s\n", message)
+		return nil, errors.New(message)
+	}
+	printVerboseMessage("Team Client created..")
+	return teamCli, nil
+}
+
+// NewScheduleClient instantiates a new OpsGenieScheduleV2Client.
+func NewScheduleClient(c *gcli.Context) (*ogcli.OpsGenieScheduleV2Client, error) {
+	cli := initialize(c)
+	scheduleCli, cliErr := cli.ScheduleV2()
+
+	if cliErr != nil {
+		message := "Can not create the schedule client. " + cliErr.Error()
+		fmt.Printf("%s\n", message)
+		return nil, errors.New(message)
+	}
+	printVerboseMessage("Schedule Client created..")
+	return scheduleCli, nil
+}
+
+// NewEscalationClient instantiates a new OpsGenieEscalationClient.
+func NewEscalationClient(c *gcli.Context) (*ogcli.OpsGenieEscalationClient, error) {
+	cli := initialize(c)
+	escalationCli, cliErr := cli.Escalation()
+
+	if cliErr != nil {
+		message := "Can not create the escalation client. " + cliErr.Error()
+		fmt.Printf("%s\n", message)
+		return nil, errors.New(message)
+	}
+	printVerboseMessage("Escalation Client created..")
+	return escalationCli, nil
+}
+
 /*
 The 'getAlert' command returns a GetAlertResponse object.
 The 'ResultToYaml' function is called whenever "output-format" parameter is
@@ -282,11 +367,246 @@ func resultToJSON(data interface{}, pretty bool) (string, error) {
 	return string(b), nil
 }
 
+// tabular is implemented by results that choose their own columns for the table output format.
+type tabular interface {
+	tableHeaders() []string
+	tableRows() [][]string
+}
+
+/*
+The 'resultToTable' function is called whenever "output-format" parameter is
+set to table. Results implementing 'tabular' are printed with their own columns.
+Other objects are printed as key/value pairs and lists of objects get a column
+for every field that holds a plain value.
+*/
+func resultToTable(data interface{}) (string, error) {
+	var headers []string
+	var rows [][]string
+	if t, ok := data.(tabular); ok {
+		headers = t.tableHeaders()
+		rows = t.tableRows()
+	} else {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return "", errors.New("Can not marshal the response into table format. " + err.Error())
+		}
+		var generic interface{}
+		if err := json.Unmarshal(b, &generic); err != nil {
+			return "", errors.New("Can not marshal the response into table format. " + err.Error())
+		}
+		headers, rows = genericTable(generic)
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(headers, "\t")))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		return "", errors.New("Can not marshal the response into table format. " + err.Error())
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func genericTable(data interface{}) ([]string, [][]string) {
+	switch value := data.(type) {
+	case []interface{}:
+		var headers []string
+		seen := map[string]bool{}
+		for _, item := range value {
+			if object, ok := item.(map[string]interface{}); ok {
+				for key, field := range object {
+					if !seen[key] && isScalar(field) {
+						seen[key] = true
+						headers = append(headers, key)
+					}
+				}
+			}
+		}
+		sort.Strings(headers)
+		var rows [][]string
+		for _, item := range value {
+			object, _ := item.(map[string]interface{})
+			row := make([]string, len(headers))
+			for i, key := range headers {
+				row[i] = tableCell(object[key])
+			}
+			rows = append(rows, row)
+		}
+		return headers, rows
+	case map[string]interface{}:
+		var keys []string
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var rows [][]string
+		for _, key := range keys {
+			rows = append(rows, []string{key, tableCell(value[key])})
+		}
+		return []string{"field", "value"}, rows
+	default:
+		return []string{"value"}, [][]string{{tableCell(value)}}
+	}
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+	return true
+}
+
+func tableCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+/*
+The 'printResult' function prints the given data in the format set by the
+"output-format" parameter. It defaults to JSON, which is indented when the
+"pretty" flag is provided alongside. Unknown formats are rejected.
+*/
+func printResult(c *gcli.Context, data interface{}) {
+	outputFormat := strings.ToLower(c.String("output-format"))
+	printVerboseMessage("Will print the result as " + outputFormat)
+	output, err := formatResult(data, outputFormat, c.IsSet("pretty"))
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("%s\n", output)
+}
+
+// formatResult converts the data into the given output format, which is one of json, yaml or table.
+func formatResult(data interface{}, outputFormat string, pretty bool) (string, error) {
+	switch outputFormat {
+	case "table":
+		return resultToTable(data)
+	case "yaml":
+		return resultToYAML(data)
+	case "json", "":
+		return resultToJSON(data, pretty)
+	}
+	return "", errors.New("Invalid output format " + outputFormat + ", specify one of json, yaml or table")
+}
+
 func readConfigFile(c *gcli.Context) {
+	loadConfigFile(c)
+	validateConfig(c)
+}
+
+// recordedProfile and recordedEndpoint take precedence over the --profile and --region flags when set,
+// so that the reconcile command re-enables every integration/policy at the account it was disabled at.
+var (
+	recordedProfile  = ""
+	recordedEndpoint = ""
+)
+
+// useRecordedAccount makes the clients created afterwards use the profile and the endpoint of the entry.
+// The flags are used again for an entry without them.
+func useRecordedAccount(entry pendingEnable) {
+	recordedProfile = entry.Profile
+	recordedEndpoint = entry.Endpoint
+}
+
+// regionIgnoredWarned is set once the warning about an ignored --region flag is printed.
+var regionIgnoredWarned = false
+
+// resolveEndpoint returns the API base URL given by opsgenie.api.url, --region or the region key.
+// It warns when --region is ignored because opsgenie.api.url is set.
+func resolveEndpoint(c *gcli.Context) cfg.Endpoint {
+	if recordedEndpoint != "" {
+		printVerboseMessage("Will send requests to " + recordedEndpoint + ", recorded with the scheduled re-enable")
+		return cfg.Endpoint{URL: recordedEndpoint, Source: "the scheduled re-enable"}
+	}
+	region, _ := getVal("region", c)
+	endpoint, err := cfg.ResolveEndpoint(region)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	if region != "" && endpoint.Region == "" && !regionIgnoredWarned {
+		regionIgnoredWarned = true
+		fmt.Fprintf(os.Stderr, "WARNING: --region %s is ignored, requests are sent to %s set by %s\n",
+			region, endpoint.URL, endpoint.Source)
+	}
+	printVerboseMessage("Will send requests to " + endpoint.URL + ", set by " + endpoint.Source)
+	return endpoint
+}
+
+// apiKeyGiven returns whether the api key is given by the --apiKey flag or a LAMP_API_KEY* environment variable,
+// in which case lamp can run without a configuration file.
+func apiKeyGiven(c *gcli.Context) bool {
+	for _, key := range []string{"apiKey", "apiKeyFile", "apiKeyCommand"} {
+		if os.Getenv(cfg.EnvName(key)) != "" {
+			return true
+		}
+	}
+	return c.IsSet("apiKey")
+}
+
+func loadConfigFile(c *gcli.Context) {
 	cfg.Verbose = verbose
+	cfg.SetLogCommand(c.Command.FullName())
 	if val, success := getVal("config", c); success {
 		cfg.LoadConfigFromGivenPath(val)
 	} else {
 		cfg.LoadConfiguration()
 	}
+	profile, _ := getVal("profile", c)
+	if recordedProfile != "" {
+		profile = recordedProfile
+	}
+	if err := cfg.SelectProfile(profile); err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	cfg.ConfigureLog()
+}
+
+// configValidated is set once the configuration is validated, since every client of a command reads it again.
+var configValidated = false
+
+// validateConfig stops the execution when the configuration has problems, unless --lenient is given
+// in which case the problems are printed as warnings. A missing configuration file is a problem unless
+// the api key is given by the --apiKey flag or the environment.
+func validateConfig(c *gcli.Context) {
+	if configValidated {
+		return
+	}
+	configValidated = true
+
+	problems, warnings := cfg.Validate()
+	if len(cfg.LoadedPaths()) == 0 && !c.IsSet("config") && !apiKeyGiven(c) {
+		problems = append(problems, errors.New("No configuration file is found, create one with 'lamp config init'"))
+	}
+	if c.IsSet("lenient") {
+		warnings = append(warnings, problems...)
+		problems = nil
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", warning.Error())
+	}
+	if len(problems) == 0 {
+		return
+	}
+	for _, problem := range problems {
+		fmt.Printf("%s\n", problem.Error())
+	}
+	fmt.Printf("Fix the configuration, or use --lenient to ignore these problems\n")
+	os.Exit(1)
 }
@@ -0,0 +1,111 @@
+package command
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"testing"
+
+	gcli "github.com/codegangsta/cli"
+)
+
+// newTestContext returns a context with the given flags set, as they would be given on the command line.
+func newTestContext(t *testing.T, flags map[string]string) *gcli.Context {
+	return newTestSliceContext(t, flags, nil)
+}
+
+// newTestSliceContext returns a context with the given flags and repeated flags set.
+func newTestSliceContext(t *testing.T, flags map[string]string, slices map[string][]string) *gcli.Context {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	for name := range flags {
+		set.String(name, "", "")
+	}
+	for name := range slices {
+		set.Var(&gcli.StringSlice{}, name, "")
+	}
+	for name, value := range flags {
+		if err := set.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, values := range slices {
+		for _, value := range values {
+			if err := set.Set(name, value); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return gcli.NewContext(nil, set, nil)
+}
+
+// captureStdout returns what f prints to the standard output.
+func captureStdout(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+	}()
+	f()
+	w.Close()
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestPrintResult(t *testing.T) {
+	type result struct {
+		Name     string   `json:"name"`
+		Interval int      `json:"interval"`
+		Enabled  bool     `json:"enabled"`
+		Tags     []string `json:"tags,omitempty"`
+	}
+	data := result{Name: "backup", Interval: 26, Enabled: true}
+	tests := []struct {
+		name  string
+		flags map[string]string
+		want  string
+	}{
+		{
+			name:  "json by default",
+			flags: map[string]string{},
+			want:  `{"name":"backup","interval":26,"enabled":true}` + "\n",
+		},
+		{
+			name:  "pretty json",
+			flags: map[string]string{"output-format": "json", "pretty": "true"},
+			want:  "{\n    \"name\": \"backup\",\n    \"interval\": 26,\n    \"enabled\": true\n}\n",
+		},
+		{
+			name:  "yaml",
+			flags: map[string]string{"output-format": "YAML"},
+			want:  "name: backup\ninterval: 26\nenabled: true\ntags: []\n\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestContext(t, test.flags)
+			got := captureStdout(t, func() {
+				printResult(c, data)
+			})
+			if got != test.want {
+				t.Errorf("printResult() printed\n%q\nwant\n%q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatResultRejectsUnknownFormats(t *testing.T) {
+	for _, format := range []string{"xml", "csv", "jsn"} {
+		_, err := formatResult(map[string]string{"name": "backup"}, format, false)
+		if err == nil || err.Error() != "Invalid output format "+format+", specify one of json, yaml or table" {
+			t.Errorf("formatResult(%q) error = %v", format, err)
+		}
+	}
+}
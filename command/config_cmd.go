@@ -0,0 +1,255 @@
+package command
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gcli "github.com/codegangsta/cli"
+	"github.com/opsgenie/opsgenie-lamp/cfg"
+)
+
+// ListProfilesAction prints the profiles defined in the configuration file and marks the selected one.
+func ListProfilesAction(c *gcli.Context) {
+	readConfiguration(c)
+
+	active := cfg.ActiveProfile()
+	profiles := profileList{{Name: "default", Active: active == "default", APIURL: profileAPIURL("")}}
+	for _, name := range cfg.ProfileNames() {
+		profiles = append(profiles, profile{Name: name, Active: name == active, APIURL: profileAPIURL(name)})
+	}
+	printResult(c, profiles)
+}
+
+// profileAPIURL returns the opsgenie.api.url of the profile, or the URL of its region.
+func profileAPIURL(name string) string {
+	if apiURL := cfg.GetInProfile(name, "opsgenie.api.url"); apiURL != "" {
+		return apiURL
+	}
+	apiURL, _ := cfg.RegionURL(cfg.GetInProfile(name, "region"))
+	return apiURL
+}
+
+// ConfigInitAction asks for the basic settings and writes them to a new configuration file.
+func ConfigInitAction(c *gcli.Context) {
+	path := cfg.DefaultConfigPath()
+	if val, success := getVal("config", c); success {
+		path = val
+	}
+	if _, err := os.Stat(path); err == nil && !c.IsSet("force") {
+		fmt.Printf("%s already exists, use --force to overwrite it\n", path)
+		os.Exit(1)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	var settings [][2]string
+	for _, question := range configInitQuestions {
+		answer, err := ask(reader, question)
+		if err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
+		}
+		if answer != "" {
+			settings = append(settings, [2]string{question.key, answer})
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	header := "# Lamp configuration. Keys that are not set here use their defaults.\n"
+	if err := ioutil.WriteFile(path, []byte(header), 0600); err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	for _, setting := range settings {
+		if err := cfg.SetValue(path, "", setting[0], setting[1]); err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("Configuration written to %s\n", path)
+}
+
+// ConfigGetAction prints the effective value of the given key. It exits with 1 when the key is not set.
+func ConfigGetAction(c *gcli.Context) {
+	if len(c.Args()) != 1 {
+		fmt.Printf("Key to get must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	readConfiguration(c)
+
+	val := cfg.Get(c.Args()[0])
+	if val == "" {
+		os.Exit(1)
+	}
+	fmt.Printf("%s\n", val)
+}
+
+// ConfigSetAction sets the given key in the configuration file, keeping the comments of the file.
+// The file is --config, or the configuration file read with the highest precedence, or the file of the user.
+// The key is set in the section of the profile given by --profile, or outside of any profile.
+func ConfigSetAction(c *gcli.Context) {
+	if len(c.Args()) != 2 {
+		fmt.Printf("Key and value to set must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	if c.IsSet("v") {
+		verbose = true
+	}
+	cfg.Verbose = verbose
+
+	path, success := getVal("config", c)
+	if !success {
+		cfg.LoadConfiguration()
+		path = cfg.DefaultConfigPath()
+		if paths := cfg.LoadedPaths(); len(paths) > 0 {
+			path = paths[len(paths)-1]
+		}
+	}
+	profile, _ := getVal("profile", c)
+	key, value := c.Args()[0], c.Args()[1]
+	if err := cfg.SetValue(path, profile, key, value); err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage(key + " is set in " + path)
+}
+
+// ConfigViewAction prints the effective configuration with the source of every value. Secrets are redacted.
+func ConfigViewAction(c *gcli.Context) {
+	readConfiguration(c)
+	printResult(c, configView{
+		Files:    cfg.LoadedPaths(),
+		Profile:  cfg.ActiveProfile(),
+		Settings: cfg.EffectiveSettings(),
+	})
+}
+
+// ConfigValidateAction checks the configuration files and prints every problem found. It exits with 1
+// when there are problems, whether --lenient is given or not.
+func ConfigValidateAction(c *gcli.Context) {
+	if c.IsSet("v") {
+		verbose = true
+	}
+	loadConfigFile(c)
+
+	problems, warnings := cfg.Validate()
+	for _, warning := range warnings {
+		fmt.Printf("WARNING: %s\n", warning.Error())
+	}
+	if len(cfg.LoadedPaths()) == 0 {
+		problems = append(problems, errors.New("No configuration file is found"))
+	}
+	if apiKey, err := cfg.GetSecret("apiKey"); err != nil {
+		problems = append(problems, err)
+	} else if apiKey == "" {
+		problems = append(problems, errors.New("apiKey is not set"))
+	}
+	for _, problem := range problems {
+		fmt.Printf("%s\n", problem.Error())
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+	fmt.Printf("Configuration is valid, read from: %s\n", strings.Join(cfg.LoadedPaths(), ", "))
+}
+
+// readConfiguration reads the configuration file for the commands that do not create an OpsGenie client.
+func readConfiguration(c *gcli.Context) {
+	if c.IsSet("v") {
+		verbose = true
+	}
+	readConfigFile(c)
+}
+
+type configInitQuestion struct {
+	key      string
+	prompt   string
+	def      string
+	required bool
+}
+
+var configInitQuestions = []configInitQuestion{
+	{key: "apiKey", prompt: "API key, or env:VAR to read it from the environment variable VAR", required: true},
+	{key: "region", prompt: "OpsGenie region: us, eu or sandbox", def: "us"},
+	{key: "user", prompt: "Default user of the executions"},
+	{key: "lamp.log.level", prompt: "Log level", def: "warn"},
+}
+
+// ask prompts until an answer is given for a required question. An empty answer takes the default.
+func ask(reader *bufio.Reader, question configInitQuestion) (string, error) {
+	for {
+		if question.def != "" {
+			fmt.Printf("%s [%s]: ", question.prompt, question.def)
+		} else {
+			fmt.Printf("%s: ", question.prompt)
+		}
+		answer, err := reader.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			answer = question.def
+		}
+		if answer != "" || !question.required {
+			return answer, nil
+		}
+		if err == io.EOF {
+			return "", errors.New(question.key + " must be provided")
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+type profile struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+	APIURL string `json:"apiUrl,omitempty"`
+}
+
+// profileList prints configuration profiles in table output format.
+type profileList []profile
+
+func (l profileList) tableHeaders() []string {
+	return []string{"profile", "active", "apiUrl"}
+}
+
+func (l profileList) tableRows() [][]string {
+	var rows [][]string
+	for _, p := range l {
+		active := ""
+		if p.Active {
+			active = "*"
+		}
+		rows = append(rows, []string{p.Name, active, p.APIURL})
+	}
+	return rows
+}
+
+// configView prints the effective settings in table output format.
+type configView struct {
+	Files    []string      `json:"files"`
+	Profile  string        `json:"profile"`
+	Settings []cfg.Setting `json:"settings"`
+}
+
+func (v configView) tableHeaders() []string {
+	return []string{"key", "value", "source"}
+}
+
+func (v configView) tableRows() [][]string {
+	var rows [][]string
+	for _, s := range v.Settings {
+		rows = append(rows, []string{s.Key, s.Value, s.Source})
+	}
+	return rows
+}
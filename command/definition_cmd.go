@@ -0,0 +1,391 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	gcli "github.com/codegangsta/cli"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// definitionStore reads and writes the full definitions of the integrations or policies at OpsGenie.
+type definitionStore interface {
+	list() (map[string]string, error)
+	get(id string) (map[string]interface{}, error)
+	create(definition map[string]interface{}) error
+	update(id string, definition map[string]interface{}) error
+}
+
+type integrationStore struct {
+	cli *restClient
+}
+
+func (s integrationStore) list() (map[string]string, error) {
+	integrations, err := s.cli.listIntegrations("", "")
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]string{}
+	for _, i := range integrations {
+		ids[i.ID] = i.Name
+	}
+	return ids, nil
+}
+
+func (s integrationStore) get(id string) (map[string]interface{}, error) {
+	return s.cli.getIntegration(id)
+}
+
+func (s integrationStore) create(definition map[string]interface{}) error {
+	return s.cli.do("POST", "/v2/integrations", nil, definition, nil)
+}
+
+func (s integrationStore) update(id string, definition map[string]interface{}) error {
+	return s.cli.do("PUT", "/v2/integrations/"+url.PathEscape(id), nil, definition, nil)
+}
+
+type policyStore struct {
+	cli    *restClient
+	teamID string
+}
+
+func (s policyStore) list() (map[string]string, error) {
+	policies, err := s.cli.listPolicies(s.teamID)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]string{}
+	for _, p := range policies {
+		ids[p.ID] = p.Name
+	}
+	return ids, nil
+}
+
+func (s policyStore) get(id string) (map[string]interface{}, error) {
+	return s.cli.getPolicy(id, s.teamID)
+}
+
+func (s policyStore) create(definition map[string]interface{}) error {
+	return s.cli.do("POST", "/v2/policies", teamQuery(s.teamID), definition, nil)
+}
+
+func (s policyStore) update(id string, definition map[string]interface{}) error {
+	return s.cli.do("PUT", "/v2/policies/"+url.PathEscape(id), teamQuery(s.teamID), definition, nil)
+}
+
+func grabDefinitionStore(c *gcli.Context) (string, definitionStore) {
+	kind, _ := getVal("type", c)
+	switch kind {
+	case "integration":
+		return kind, integrationStore{cli: newRESTClient(c)}
+	case "policy":
+		teamID, _ := getVal("teamId", c)
+		return kind, policyStore{cli: newRESTClient(c), teamID: teamID}
+	}
+	fmt.Printf("Invalid type option %s, specify either integration or policy\n", kind)
+	gcli.ShowCommandHelp(c, c.Command.Name)
+	os.Exit(1)
+	return "", nil
+}
+
+// ExportConfigAction writes the full definition of every integration/policy into a YAML file under the --out directory.
+func ExportConfigAction(c *gcli.Context) {
+	kind, store := grabDefinitionStore(c)
+	outDir, success := getVal("out", c)
+	if !success {
+		fmt.Printf("Output directory must be provided with --out\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		fmt.Printf("Could not create output directory: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	ids, err := store.list()
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Found " + strconv.Itoa(len(ids)) + " " + kind + " definitions, exporting..")
+
+	usedNames := map[string]bool{}
+	failed := false
+	for _, id := range sortedKeys(ids) {
+		definition, err := store.get(id)
+		if err != nil {
+			fmt.Printf("Could not get %s %s: %s\n", kind, ids[id], err.Error())
+			failed = true
+			continue
+		}
+		fileName := unsafeFileNameChars.ReplaceAllString(ids[id], "_")
+		if fileName == "" || usedNames[fileName] {
+			fileName = fileName + "-" + id
+		}
+		usedNames[fileName] = true
+
+		content, err := yaml.Marshal(definition)
+		if err != nil {
+			fmt.Printf("Could not marshal %s %s into YAML format: %s\n", kind, ids[id], err.Error())
+			failed = true
+			continue
+		}
+		path := filepath.Join(outDir, fileName+".yaml")
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			fmt.Printf("Could not write %s: %s\n", path, err.Error())
+			failed = true
+			continue
+		}
+		fmt.Printf("Exported %s %s to %s\n", kind, ids[id], path)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// ImportConfigAction creates or updates the integrations/policies defined in the YAML files given with --file or --dir.
+// Definitions are matched to the existing ones by id, then by name. With --diff the changes are only printed.
+func ImportConfigAction(c *gcli.Context) {
+	kind, store := grabDefinitionStore(c)
+	paths, err := grabDefinitionFiles(c)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	previewOnly := c.IsSet("diff")
+
+	ids, err := store.list()
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	idsByName := map[string]string{}
+	for id, name := range ids {
+		idsByName[name] = id
+	}
+
+	failed := false
+	for _, path := range paths {
+		definition, err := readDefinitionFile(path)
+		if err != nil {
+			fmt.Printf("%s\n", err.Error())
+			failed = true
+			continue
+		}
+		name, _ := definition["name"].(string)
+		id, _ := definition["id"].(string)
+		if _, exists := ids[id]; !exists {
+			id = idsByName[name]
+		}
+
+		if id == "" {
+			delete(definition, "id")
+			fmt.Printf("%s %s will be created from %s\n", capitalize(kind), name, path)
+			if previewOnly {
+				printDefinitionDiff(nil, definition)
+				continue
+			}
+			if err := store.create(definition); err != nil {
+				fmt.Printf("Could not create %s %s: %s\n", kind, name, err.Error())
+				failed = true
+				continue
+			}
+			fmt.Printf("%s %s created successfuly\n", capitalize(kind), name)
+			continue
+		}
+
+		current, err := store.get(id)
+		if err != nil {
+			fmt.Printf("Could not get %s %s: %s\n", kind, name, err.Error())
+			failed = true
+			continue
+		}
+		definition["id"] = id
+		if !reportDefinitionChanges(kind, name, path, current, definition) {
+			continue
+		}
+		if previewOnly {
+			continue
+		}
+		if err := store.update(id, definition); err != nil {
+			fmt.Printf("Could not update %s %s: %s\n", kind, name, err.Error())
+			failed = true
+			continue
+		}
+		fmt.Printf("%s %s updated successfuly\n", capitalize(kind), name)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// reportDefinitionChanges prints the changes the file makes to the current definition and reports whether there are any.
+func reportDefinitionChanges(kind string, name string, path string, current map[string]interface{}, definition map[string]interface{}) bool {
+	before := flattenDefinition("", current)
+	after := flattenDefinition("", definition)
+	if len(diffDefinitions(before, after)) == 0 {
+		fmt.Printf("%s %s is up to date with %s\n", capitalize(kind), name, path)
+		return false
+	}
+	fmt.Printf("%s %s will be updated from %s\n", capitalize(kind), name, path)
+	printDefinitionDiff(current, definition)
+	return true
+}
+
+// printDefinitionDiff prints the changed fields of a definition, prefixed with - for the old and + for the new values.
+func printDefinitionDiff(current map[string]interface{}, definition map[string]interface{}) {
+	for _, line := range diffDefinitions(flattenDefinition("", current), flattenDefinition("", definition)) {
+		fmt.Printf("    %s\n", line)
+	}
+}
+
+func diffDefinitions(before map[string]string, after map[string]string) []string {
+	keys := map[string]string{}
+	for key := range before {
+		keys[key] = ""
+	}
+	for key := range after {
+		keys[key] = ""
+	}
+	var lines []string
+	for _, key := range sortedKeys(keys) {
+		oldValue, hadOld := before[key]
+		newValue, hasNew := after[key]
+		if hadOld && hasNew && oldValue == newValue {
+			continue
+		}
+		if hadOld {
+			lines = append(lines, "- "+key+": "+oldValue)
+		}
+		if hasNew {
+			lines = append(lines, "+ "+key+": "+newValue)
+		}
+	}
+	return lines
+}
+
+// flattenDefinition maps every plain value in the definition to its dotted path, such as "filter.conditions.0.field".
+func flattenDefinition(prefix string, value interface{}) map[string]string {
+	flat := map[string]string{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			for path, leaf := range flattenDefinition(joinPath(prefix, key), field) {
+				flat[path] = leaf
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			for path, leaf := range flattenDefinition(joinPath(prefix, strconv.Itoa(i)), item) {
+				flat[path] = leaf
+			}
+		}
+	case nil:
+		if prefix != "" {
+			flat[prefix] = ""
+		}
+	default:
+		flat[prefix] = formatLeaf(v)
+	}
+	return flat
+}
+
+func joinPath(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// formatLeaf formats numbers decoded from JSON and from YAML alike, so that 5 and 5.0 compare equal.
+func formatLeaf(value interface{}) string {
+	switch v := value.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return tableCell(value)
+}
+
+func grabDefinitionFiles(c *gcli.Context) ([]string, error) {
+	if val, success := getVal("file", c); success {
+		return []string{val}, nil
+	}
+	dir, success := getVal("dir", c)
+	if !success {
+		return nil, errors.New("Either file or dir must be provided")
+	}
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+	if len(paths) == 0 {
+		return nil, errors.New("Could not find any YAML file in " + dir)
+	}
+	return paths, nil
+}
+
+func readDefinitionFile(path string) (map[string]interface{}, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.New("Could not read " + path + ". " + err.Error())
+	}
+	var raw interface{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, errors.New("Could not parse " + path + ". " + err.Error())
+	}
+	definition, ok := normalizeYAML(raw).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("Could not parse " + path + ". The definition must be a YAML mapping")
+	}
+	if name, _ := definition["name"].(string); name == "" {
+		return nil, errors.New("Could not parse " + path + ". The definition must have a name")
+	}
+	return definition, nil
+}
+
+// normalizeYAML converts the map[interface{}]interface{} values decoded by yaml into JSON compatible maps.
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := map[string]interface{}{}
+		for key, field := range v {
+			converted[fmt.Sprintf("%v", key)] = normalizeYAML(field)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	}
+	return value
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,270 @@
+package command
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlattenDefinition(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition interface{}
+		want       map[string]string
+	}{
+		{
+			name:       "nil definition",
+			definition: nil,
+			want:       map[string]string{},
+		},
+		{
+			name: "nested maps and lists",
+			definition: map[string]interface{}{
+				"name":    "Night",
+				"enabled": true,
+				"order":   float64(2),
+				"filter": map[string]interface{}{
+					"type": "match-all-conditions",
+					"conditions": []interface{}{
+						map[string]interface{}{"field": "message", "expectedValue": "db"},
+					},
+				},
+				"description": nil,
+			},
+			want: map[string]string{
+				"name":                              "Night",
+				"enabled":                           "true",
+				"order":                             "2",
+				"filter.type":                       "match-all-conditions",
+				"filter.conditions.0.field":         "message",
+				"filter.conditions.0.expectedValue": "db",
+				"description":                       "",
+			},
+		},
+		{
+			name:       "yaml and json numbers are equal",
+			definition: map[string]interface{}{"a": 5, "b": float64(5), "c": int64(5), "d": uint64(5)},
+			want:       map[string]string{"a": "5", "b": "5", "c": "5", "d": "5"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := flattenDefinition("", test.definition); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("flattenDefinition() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiffDefinitions(t *testing.T) {
+	tests := []struct {
+		name   string
+		before map[string]string
+		after  map[string]string
+		want   []string
+	}{
+		{
+			name:   "no changes",
+			before: map[string]string{"name": "web", "enabled": "true"},
+			after:  map[string]string{"name": "web", "enabled": "true"},
+			want:   nil,
+		},
+		{
+			name:   "created",
+			before: nil,
+			after:  map[string]string{"name": "web", "type": "API"},
+			want:   []string{"+ name: web", "+ type: API"},
+		},
+		{
+			name:   "changed, added and removed fields",
+			before: map[string]string{"name": "web", "enabled": "true", "owner": "ops"},
+			after:  map[string]string{"name": "web", "enabled": "false", "region": "eu"},
+			want:   []string{"- enabled: true", "+ enabled: false", "- owner: ops", "+ region: eu"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := diffDefinitions(test.before, test.after); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("diffDefinitions() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReportDefinitionChanges(t *testing.T) {
+	current := map[string]interface{}{"id": "1", "name": "web", "enabled": true}
+	var changed bool
+	out := captureStdout(t, func() {
+		changed = reportDefinitionChanges("integration", "web", "web.yaml", current, map[string]interface{}{"id": "1", "name": "web", "enabled": true})
+	})
+	if changed || out != "Integration web is up to date with web.yaml\n" {
+		t.Errorf("reportDefinitionChanges() = %t, printed %q", changed, out)
+	}
+
+	out = captureStdout(t, func() {
+		changed = reportDefinitionChanges("integration", "web", "web.yaml", current, map[string]interface{}{"id": "1", "name": "web", "enabled": false})
+	})
+	want := "Integration web will be updated from web.yaml\n" +
+		"    - enabled: true\n" +
+		"    + enabled: false\n"
+	if !changed || out != want {
+		t.Errorf("reportDefinitionChanges() = %t, printed %q, want %q", changed, out, want)
+	}
+}
+
+func TestReadDefinitionFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:    "nested definition",
+			content: "name: web\ntype: API\nfilter:\n  conditions:\n    - field: message\n      not: false\n",
+			want: map[string]interface{}{
+				"name": "web",
+				"type": "API",
+				"filter": map[string]interface{}{
+					"conditions": []interface{}{map[string]interface{}{"field": "message", "not": false}},
+				},
+			},
+		},
+		{
+			name:    "not a mapping",
+			content: "- web\n",
+			wantErr: "The definition must be a YAML mapping",
+		},
+		{
+			name:    "missing name",
+			content: "type: API\n",
+			wantErr: "The definition must have a name",
+		},
+		{
+			name:    "invalid yaml",
+			content: "name: [web\n",
+			wantErr: "Could not parse",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "definition.yaml")
+			if err := ioutil.WriteFile(path, []byte(test.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readDefinitionFile(path)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("readDefinitionFile() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("readDefinitionFile() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGrabDefinitionFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.yml", "a.yaml", "notes.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("name: x\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := grabDefinitionFiles(newTestContext(t, map[string]string{"dir": dir}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yml")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("grabDefinitionFiles() = %v, want %v", got, want)
+	}
+
+	if _, err := grabDefinitionFiles(newTestContext(t, map[string]string{"dir": t.TempDir()})); err == nil {
+		t.Error("grabDefinitionFiles() of an empty directory returned no error")
+	}
+	if _, err := grabDefinitionFiles(newTestContext(t, map[string]string{})); err == nil {
+		t.Error("grabDefinitionFiles() without file or dir returned no error")
+	}
+}
+
+func TestDefinitionStores(t *testing.T) {
+	var requests []string
+	cli := newTestRESTClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.RequestURI+" "+string(body))
+		switch r.URL.Path {
+		case "/v2/integrations":
+			w.Write([]byte(`{"data": [{"id": "1", "name": "web"}]}`))
+		case "/v2/policies/alert":
+			w.Write([]byte(`{"data": [{"id": "2", "name": "night"}]}`))
+		default:
+			w.Write([]byte(`{"data": {"id": "1", "name": "web"}}`))
+		}
+	})
+	definition := map[string]interface{}{"name": "web"}
+
+	tests := []struct {
+		name      string
+		store     definitionStore
+		wantIDs   map[string]string
+		wantCalls []string
+	}{
+		{
+			name:    "integrations",
+			store:   integrationStore{cli: cli},
+			wantIDs: map[string]string{"1": "web"},
+			wantCalls: []string{
+				"GET /v2/integrations ",
+				"GET /v2/integrations/1 ",
+				`POST /v2/integrations {"name":"web"}`,
+				`PUT /v2/integrations/1 {"name":"web"}`,
+			},
+		},
+		{
+			name:    "team policies",
+			store:   policyStore{cli: cli, teamID: "t1"},
+			wantIDs: map[string]string{"2": "night"},
+			wantCalls: []string{
+				"GET /v2/policies/alert?teamId=t1 ",
+				"GET /v2/policies/1?teamId=t1 ",
+				`POST /v2/policies?teamId=t1 {"name":"web"}`,
+				`PUT /v2/policies/1?teamId=t1 {"name":"web"}`,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests = nil
+			ids, err := test.store.list()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ids, test.wantIDs) {
+				t.Errorf("list() = %v, want %v", ids, test.wantIDs)
+			}
+			got, err := test.store.get("1")
+			if err != nil || !reflect.DeepEqual(got, map[string]interface{}{"id": "1", "name": "web"}) {
+				t.Errorf("get() = %v, %v", got, err)
+			}
+			if err := test.store.create(definition); err != nil {
+				t.Errorf("create() error = %v", err)
+			}
+			if err := test.store.update("1", definition); err != nil {
+				t.Errorf("update() error = %v", err)
+			}
+			if !reflect.DeepEqual(requests, test.wantCalls) {
+				t.Errorf("requests = %q, want %q", requests, test.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	gcli "github.com/codegangsta/cli"
+	"github.com/opsgenie/opsgenie-go-sdk/escalation"
+)
+
+// ListEscalationsAction retrieves the escalations defined at OpsGenie.
+func ListEscalationsAction(c *gcli.Context) {
+	cli, err := NewEscalationClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	printVerboseMessage("List escalations request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.List(escalation.ListEscalationsRequest{})
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Escalations listed successfully.")
+	printResult(c, escalationList(resp.Escalations))
+}
+
+// GetEscalationAction retrieves the specified escalation with its rules from OpsGenie.
+func GetEscalationAction(c *gcli.Context) {
+	cli, err := NewEscalationClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	printVerboseMessage("Get escalation request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.Get(grabEscalationIdentifier(c))
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Got escalation successfully.")
+	printResult(c, escalationRuleTable(resp.Escalation))
+}
+
+// CreateEscalationAction creates an escalation at OpsGenie.
+func CreateEscalationAction(c *gcli.Context) {
+	req := escalation.CreateEscalationRequest{}
+	if val, success := getVal("name", c); success {
+		req.Name = val
+	} else {
+		fmt.Printf("Name of the escalation must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	if val, success := getVal("description", c); success {
+		req.Description = val
+	}
+	if val, success := getVal("ownerTeam", c); success {
+		req.OwnerTeam = &escalation.OwnerTeam{Name: val}
+	}
+	req.Rules = grabEscalationRules(c)
+	if len(req.Rules) == 0 {
+		fmt.Printf("At least one rule must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+
+	cli, err := NewEscalationClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	printVerboseMessage("Create escalation request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.Create(req)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Escalation created successfully.")
+	fmt.Printf("id=%s\n", resp.ID)
+}
+
+// UpdateEscalationAction updates the specified escalation at OpsGenie. The rules are replaced only if --rule is given.
+func UpdateEscalationAction(c *gcli.Context) {
+	rules := grabEscalationRules(c)
+
+	cli, err := NewEscalationClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	current, err := cli.Get(grabEscalationIdentifier(c))
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	req := escalation.UpdateEscalationRequest{
+		ID:          current.ID,
+		Name:        current.Name,
+		Description: current.Description,
+		OwnerTeam:   current.OwnerTeam,
+		Rules:       current.Rules,
+	}
+	if val, success := getVal("rename", c); success {
+		req.Name = val
+	}
+	if val, success := getVal("description", c); success {
+		req.Description = val
+	}
+	if val, success := getVal("ownerTeam", c); success {
+		req.OwnerTeam = &escalation.OwnerTeam{Name: val}
+	}
+	if len(rules) > 0 {
+		req.Rules = rules
+	}
+
+	printVerboseMessage("Update escalation request prepared from flags, sending request to OpsGenie..")
+
+	if _, err := cli.Update(req); err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Escalation %s updated successfuly\n", req.Name)
+}
+
+// DeleteEscalationAction deletes the specified escalation at OpsGenie.
+func DeleteEscalationAction(c *gcli.Context) {
+	cli, err := NewEscalationClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	identifier := grabEscalationIdentifier(c)
+	req := escalation.DeleteEscalationRequest{ID: identifier.ID, Name: identifier.Name}
+
+	printVerboseMessage("Delete escalation request prepared from flags, sending request to OpsGenie..")
+
+	if _, err := cli.Delete(req); err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Escalation deleted successfuly\n")
+}
+
+// grabEscalationIdentifier returns the escalation given by --id or --name, exiting when neither is provided.
+func grabEscalationIdentifier(c *gcli.Context) escalation.GetEscalationRequest {
+	req := escalation.GetEscalationRequest{}
+	if val, success := getVal("id", c); success {
+		req.ID = val
+	}
+	if val, success := getVal("name", c); success {
+		req.Name = val
+	}
+	if req.ID == "" && req.Name == "" {
+		fmt.Printf("Either id or name of the escalation must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	return req
+}
+
+// grabEscalationRules parses every --rule flag, exiting on the first malformed one.
+func grabEscalationRules(c *gcli.Context) []escalation.Rule {
+	var rules []escalation.Rule
+	for _, val := range c.StringSlice("rule") {
+		rule, err := parseEscalationRule(val)
+		if err != nil {
+			fmt.Printf("%s\n", err.Error())
+			gcli.ShowCommandHelp(c, c.Command.Name)
+			os.Exit(1)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// parseEscalationRule parses a rule given as "DELAY CONDITION TYPE[:NAME] [NOTIFY_TYPE]",
+// such as "0m if-not-acked schedule:primary" or "30m if-not-closed team:ops admins".
+func parseEscalationRule(val string) (escalation.Rule, error) {
+	rule := escalation.Rule{}
+	fields := strings.Fields(val)
+	if len(fields) < 3 || len(fields) > 4 {
+		return rule, errors.New("Invalid rule \"" + val + "\", use the form \"DELAY CONDITION TYPE[:NAME] [NOTIFY_TYPE]\"" +
+			" such as \"10m if-not-acked schedule:primary\"")
+	}
+	delay, err := time.ParseDuration(fields[0])
+	if err != nil || delay < 0 || delay%time.Minute != 0 {
+		return rule, errors.New("Invalid rule delay " + fields[0] + ", use a whole number of minutes such as 0m, 15m or 1h")
+	}
+	rule.Delay = int(delay / time.Minute)
+
+	rule.Condition = strings.ToLower(fields[1])
+	if rule.Condition != "if-not-acked" && rule.Condition != "if-not-closed" {
+		return rule, errors.New("Invalid rule condition " + fields[1] + ", use if-not-acked or if-not-closed")
+	}
+
+	parts := strings.SplitN(fields[2], ":", 2)
+	rule.Recipient.Type = strings.ToLower(parts[0])
+	switch rule.Recipient.Type {
+	case "user":
+		if len(parts) < 2 {
+			return rule, errors.New("Username of the user recipient must be given as user:USERNAME")
+		}
+		rule.Recipient.Username = parts[1]
+	case "schedule", "team", "escalation":
+		if len(parts) < 2 {
+			return rule, errors.New("Name of the " + rule.Recipient.Type + " recipient must be given as " +
+				rule.Recipient.Type + ":NAME")
+		}
+		rule.Recipient.Name = parts[1]
+	default:
+		return rule, errors.New("Invalid rule recipient type " + parts[0] + ", use user, schedule, team or escalation")
+	}
+
+	rule.NotifyType = "default"
+	if len(fields) == 4 {
+		rule.NotifyType = strings.ToLower(fields[3])
+	}
+	return rule, nil
+}
+
+// describeEscalationRecipient describes who a rule notifies in plain words.
+func describeEscalationRecipient(rule escalation.Rule) string {
+	r := rule.Recipient
+	name := r.Username
+	if name == "" {
+		name = r.Name
+	}
+	if name == "" {
+		name = r.ID
+	}
+	switch r.Type {
+	case "schedule":
+		switch rule.NotifyType {
+		case "next":
+			return "next on-call users of schedule " + name
+		case "previous":
+			return "previous on-call users of schedule " + name
+		}
+		return "on-call users of schedule " + name
+	case "team":
+		switch rule.NotifyType {
+		case "users":
+			return "members of team " + name
+		case "admins":
+			return "admins of team " + name
+		case "all":
+			return "all members of team " + name
+		}
+		return "team " + name + " through its escalation"
+	case "":
+		return name
+	}
+	return r.Type + " " + name
+}
+
+func formatEscalationDelay(minutes int) string {
+	if minutes == 0 {
+		return "immediately"
+	}
+	delay := ""
+	if minutes >= 60 {
+		delay = strconv.Itoa(minutes/60) + "h"
+	}
+	if minutes%60 != 0 {
+		delay += strconv.Itoa(minutes%60) + "m"
+	}
+	return "after " + delay
+}
+
+// escalationList prints escalations in table output format.
+type escalationList []escalation.Escalation
+
+func (l escalationList) tableHeaders() []string {
+	return []string{"id", "name", "ownerTeam", "rules", "description"}
+}
+
+func (l escalationList) tableRows() [][]string {
+	var rows [][]string
+	for _, e := range l {
+		ownerTeam := ""
+		if e.OwnerTeam != nil {
+			ownerTeam = e.OwnerTeam.Name
+		}
+		rows = append(rows, []string{e.ID, e.Name, ownerTeam, strconv.Itoa(len(e.Rules)), e.Description})
+	}
+	return rows
+}
+
+// escalationRuleTable prints the rules of an escalation step by step in table output format.
+type escalationRuleTable escalation.Escalation
+
+func (e escalationRuleTable) tableHeaders() []string {
+	return []string{"step", "when", "condition", "notify"}
+}
+
+func (e escalationRuleTable) tableRows() [][]string {
+	var rows [][]string
+	for i, rule := range e.Rules {
+		rows = append(rows, []string{strconv.Itoa(i + 1), formatEscalationDelay(rule.Delay),
+			strings.Replace(rule.Condition, "-", " ", -1), describeEscalationRecipient(rule)})
+	}
+	return rows
+}
@@ -0,0 +1,107 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/opsgenie/opsgenie-go-sdk/escalation"
+)
+
+func TestParseEscalationRule(t *testing.T) {
+	tests := []struct {
+		val     string
+		want    escalation.Rule
+		wantErr bool
+	}{
+		{
+			val: "10m if-not-acked schedule:primary",
+			want: escalation.Rule{Delay: 10, Condition: "if-not-acked", NotifyType: "default",
+				Recipient: escalation.Recipient{Type: "schedule", Name: "primary"}},
+		},
+		{
+			val: "0m IF-NOT-CLOSED user:jane@example.com",
+			want: escalation.Rule{Delay: 0, Condition: "if-not-closed", NotifyType: "default",
+				Recipient: escalation.Recipient{Type: "user", Username: "jane@example.com"}},
+		},
+		{
+			val: "1h if-not-acked team:ops:db admins",
+			want: escalation.Rule{Delay: 60, Condition: "if-not-acked", NotifyType: "admins",
+				Recipient: escalation.Recipient{Type: "team", Name: "ops:db"}},
+		},
+		{
+			val: "1h30m if-not-acked escalation:fallback",
+			want: escalation.Rule{Delay: 90, Condition: "if-not-acked", NotifyType: "default",
+				Recipient: escalation.Recipient{Type: "escalation", Name: "fallback"}},
+		},
+		{val: "90s if-not-acked escalation:fallback", wantErr: true},
+		{val: "10m if-not-acked", wantErr: true},
+		{val: "10m if-not-acked schedule:primary next extra", wantErr: true},
+		{val: "10 if-not-acked schedule:primary", wantErr: true},
+		{val: "-5m if-not-acked schedule:primary", wantErr: true},
+		{val: "10m if-acked schedule:primary", wantErr: true},
+		{val: "10m if-not-acked schedule", wantErr: true},
+		{val: "10m if-not-acked user", wantErr: true},
+		{val: "10m if-not-acked group:ops", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.val, func(t *testing.T) {
+			rule, err := parseEscalationRule(test.val)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("parseEscalationRule(%q) = %+v, want an error", test.val, rule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseEscalationRule(%q) returned %v", test.val, err)
+			}
+			if rule != test.want {
+				t.Errorf("parseEscalationRule(%q) = %+v, want %+v", test.val, rule, test.want)
+			}
+		})
+	}
+}
+
+func TestDescribeEscalationRecipient(t *testing.T) {
+	tests := []struct {
+		rule escalation.Rule
+		want string
+	}{
+		{rule: escalation.Rule{NotifyType: "default", Recipient: escalation.Recipient{Type: "schedule", Name: "primary"}},
+			want: "on-call users of schedule primary"},
+		{rule: escalation.Rule{NotifyType: "next", Recipient: escalation.Recipient{Type: "schedule", Name: "primary"}},
+			want: "next on-call users of schedule primary"},
+		{rule: escalation.Rule{NotifyType: "admins", Recipient: escalation.Recipient{Type: "team", Name: "ops"}},
+			want: "admins of team ops"},
+		{rule: escalation.Rule{NotifyType: "default", Recipient: escalation.Recipient{Type: "team", ID: "t1"}},
+			want: "team t1 through its escalation"},
+		{rule: escalation.Rule{Recipient: escalation.Recipient{Type: "user", Username: "jane@example.com"}},
+			want: "user jane@example.com"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := describeEscalationRecipient(test.rule); got != test.want {
+				t.Errorf("describeEscalationRecipient(%+v) = %q, want %q", test.rule, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatEscalationDelay(t *testing.T) {
+	tests := []struct {
+		minutes int
+		want    string
+	}{
+		{minutes: 0, want: "immediately"},
+		{minutes: 5, want: "after 5m"},
+		{minutes: 60, want: "after 1h"},
+		{minutes: 90, want: "after 1h30m"},
+		{minutes: 1500, want: "after 25h"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := formatEscalationDelay(test.minutes); got != test.want {
+				t.Errorf("formatEscalationDelay(%d) = %q, want %q", test.minutes, got, test.want)
+			}
+		})
+	}
+}
@@ -5,15 +5,29 @@ import (
 	hb "github.com/opsgenie/opsgenie-go-sdk/heartbeat"
 	"fmt"
 	"os"
+	"strconv"
 )
 
 // HeartbeatAction sends an Heartbeat signal to OpsGenie.
+// When any of the --if-* conditions is given, the signal is sent only if all of them hold,
+// otherwise it exits with ConditionNotMetExitCode.
 func HeartbeatAction(c *gcli.Context) {
 	cli, err := NewHeartbeatClient(c)
 	if err != nil {
 		os.Exit(1)
 	}
 
+	conditions, err := collectHeartbeatConditions(c)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	if err := checkHeartbeatConditions(conditions); err != nil {
+		fmt.Printf("Heartbeat is not sent, %s\n", err.Error())
+		os.Exit(ConditionNotMetExitCode)
+	}
+
 	req := hb.PingHeartbeatRequest{}
 	if val, success := getVal("name", c); success {
 		req.Name = val
@@ -28,3 +42,205 @@ func HeartbeatAction(c *gcli.Context) {
 	}
 	printVerboseMessage("Ping request has recived. RequestID: " + response.RequestID)
 }
+
+// ListHeartbeatsAction retrieves the heartbeats defined at OpsGenie.
+func ListHeartbeatsAction(c *gcli.Context) {
+	cli, err := NewHeartbeatClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	printVerboseMessage("List heartbeats request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.List(hb.ListHeartbeatsRequest{})
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Heartbeats listed successfully.")
+	printResult(c, heartbeatList(resp.Heartbeats))
+}
+
+// GetHeartbeatAction retrieves the specified heartbeat details from OpsGenie.
+func GetHeartbeatAction(c *gcli.Context) {
+	cli, err := NewHeartbeatClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	req := hb.GetHeartbeatRequest{}
+	req.Name = grabHeartbeatName(c)
+
+	printVerboseMessage("Get heartbeat request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.Get(req)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Got heartbeat successfully.")
+	printResult(c, resp.Heartbeat)
+}
+
+// CreateHeartbeatAction creates a heartbeat at OpsGenie.
+func CreateHeartbeatAction(c *gcli.Context) {
+	cli, err := NewHeartbeatClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	enabled := !c.IsSet("disabled")
+	req := hb.AddHeartbeatRequest{
+		Name:    grabHeartbeatName(c),
+		Enabled: &enabled,
+	}
+	if val, success := getVal("interval", c); success {
+		req.Interval = parseHeartbeatInterval(val)
+	}
+	if val, success := getVal("intervalUnit", c); success {
+		req.IntervalUnit = val
+	}
+	if val, success := getVal("description", c); success {
+		req.Description = val
+	}
+	if val, success := getVal("ownerTeam", c); success {
+		req.OwnerTeam = hb.OwnerTeam{Name: val}
+	}
+
+	printVerboseMessage("Create heartbeat request prepared from flags, sending request to OpsGenie..")
+
+	_, err = cli.Add(req)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Heartbeat %s created successfuly\n", req.Name)
+}
+
+// UpdateHeartbeatAction updates the specified heartbeat at OpsGenie.
+func UpdateHeartbeatAction(c *gcli.Context) {
+	cli, err := NewHeartbeatClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	req := hb.UpdateHeartbeatRequest{}
+	req.Name = grabHeartbeatName(c)
+	if val, success := getVal("interval", c); success {
+		req.Interval = parseHeartbeatInterval(val)
+	}
+	if val, success := getVal("intervalUnit", c); success {
+		req.IntervalUnit = val
+	}
+	if val, success := getVal("description", c); success {
+		req.Description = val
+	}
+	if val, success := getVal("ownerTeam", c); success {
+		req.OwnerTeam = hb.OwnerTeam{Name: val}
+	}
+
+	printVerboseMessage("Update heartbeat request prepared from flags, sending request to OpsGenie..")
+
+	_, err = cli.Update(req)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Heartbeat %s updated successfuly\n", req.Name)
+}
+
+// DeleteHeartbeatAction deletes the specified heartbeat at OpsGenie.
+func DeleteHeartbeatAction(c *gcli.Context) {
+	cli, err := NewHeartbeatClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	req := hb.DeleteHeartbeatRequest{}
+	req.Name = grabHeartbeatName(c)
+
+	printVerboseMessage("Delete heartbeat request prepared from flags, sending request to OpsGenie..")
+
+	_, err = cli.Delete(req)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Heartbeat %s deleted successfuly\n", req.Name)
+}
+
+// EnableHeartbeatAction enables the specified heartbeat at OpsGenie.
+func EnableHeartbeatAction(c *gcli.Context) {
+	cli, err := NewHeartbeatClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	req := hb.EnableHeartbeatRequest{}
+	req.Name = grabHeartbeatName(c)
+
+	printVerboseMessage("Enable heartbeat request prepared from flags, sending request to OpsGenie..")
+
+	_, err = cli.Enable(req)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Heartbeat %s enabled successfuly\n", req.Name)
+}
+
+// DisableHeartbeatAction disables the specified heartbeat at OpsGenie.
+func DisableHeartbeatAction(c *gcli.Context) {
+	cli, err := NewHeartbeatClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	req := hb.DisableHeartbeatRequest{}
+	req.Name = grabHeartbeatName(c)
+
+	printVerboseMessage("Disable heartbeat request prepared from flags, sending request to OpsGenie..")
+
+	_, err = cli.Disable(req)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Heartbeat %s disabled successfuly\n", req.Name)
+}
+
+// grabHeartbeatName returns the mandatory heartbeat name, exiting when it is not provided.
+func grabHeartbeatName(c *gcli.Context) string {
+	val, success := getVal("name", c)
+	if !success {
+		fmt.Printf("Name of the heartbeat must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	return val
+}
+
+func parseHeartbeatInterval(val string) int {
+	interval, err := strconv.Atoi(val)
+	if err != nil || interval <= 0 {
+		fmt.Printf("Invalid interval %s, it must be a positive number\n", val)
+		os.Exit(1)
+	}
+	return interval
+}
+
+// heartbeatList prints heartbeats with their state in table output format.
+type heartbeatList []hb.Heartbeat
+
+func (l heartbeatList) tableHeaders() []string {
+	return []string{"name", "enabled", "expired", "interval", "ownerTeam", "description"}
+}
+
+func (l heartbeatList) tableRows() [][]string {
+	var rows [][]string
+	for _, h := range l {
+		rows = append(rows, []string{h.Name, strconv.FormatBool(h.Enabled), strconv.FormatBool(h.Expired),
+			strconv.Itoa(h.Interval) + " " + h.IntervalUnit, h.OwnerTeam.Name, h.Description})
+	}
+	return rows
+}
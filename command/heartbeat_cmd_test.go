@@ -0,0 +1,64 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	hb "github.com/opsgenie/opsgenie-go-sdk/heartbeat"
+)
+
+func TestHeartbeatListTableRows(t *testing.T) {
+	tests := []struct {
+		name       string
+		heartbeats heartbeatList
+		want       [][]string
+	}{
+		{
+			name:       "empty",
+			heartbeats: heartbeatList{},
+			want:       nil,
+		},
+		{
+			name: "owner team and interval",
+			heartbeats: heartbeatList{{
+				Name:         "backup",
+				Description:  "nightly backup",
+				Interval:     26,
+				IntervalUnit: "hours",
+				Enabled:      true,
+				OwnerTeam:    hb.OwnerTeam{Name: "ops"},
+			}},
+			want: [][]string{{"backup", "true", "false", "26 hours", "ops", "nightly backup"}},
+		},
+		{
+			name: "expired without team",
+			heartbeats: heartbeatList{
+				{Name: "a", Interval: 10, IntervalUnit: "minutes", Expired: true},
+				{Name: "b", Interval: 1, IntervalUnit: "days", Enabled: true},
+			},
+			want: [][]string{
+				{"a", "false", "true", "10 minutes", "", ""},
+				{"b", "true", "false", "1 days", "", ""},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.heartbeats.tableRows(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("tableRows() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResultToTable(t *testing.T) {
+	got, err := resultToTable(heartbeatList{{Name: "backup", Interval: 5, IntervalUnit: "minutes", Enabled: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "NAME    ENABLED  EXPIRED  INTERVAL   OWNERTEAM  DESCRIPTION\n" +
+		"backup  true     false    5 minutes             "
+	if got != want {
+		t.Errorf("resultToTable() =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,191 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	gcli "github.com/codegangsta/cli"
+)
+
+// ConditionNotMetExitCode is the exit status of the heartbeat command when a condition does not hold and no ping is sent.
+const ConditionNotMetExitCode = 3
+
+// conditionTimeout bounds the network checks so that a hanging endpoint can not block the ping forever.
+const conditionTimeout = 10 * time.Second
+
+// heartbeatCondition is a local health check that must hold before a heartbeat ping is sent.
+type heartbeatCondition struct {
+	description string
+	check       func() error
+}
+
+// collectHeartbeatConditions builds the health checks given with the --if-* flags of the heartbeat command.
+func collectHeartbeatConditions(c *gcli.Context) ([]heartbeatCondition, error) {
+	var conditions []heartbeatCondition
+
+	for _, val := range c.StringSlice("if-file-newer-than") {
+		path, maxAge, err := parseFileAgeCondition(val)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, heartbeatCondition{
+			description: "file " + path + " is newer than " + maxAge.String(),
+			check:       func() error { return checkFileNewerThan(path, maxAge) },
+		})
+	}
+	for _, val := range c.StringSlice("if-port-open") {
+		address := val
+		conditions = append(conditions, heartbeatCondition{
+			description: "port " + address + " is reachable",
+			check:       func() error { return checkPortOpen(address) },
+		})
+	}
+	for _, val := range c.StringSlice("if-http-ok") {
+		url := val
+		conditions = append(conditions, heartbeatCondition{
+			description: url + " returns 2xx",
+			check:       func() error { return checkHTTPOk(url) },
+		})
+	}
+	for _, val := range c.StringSlice("if-process-running") {
+		name := val
+		conditions = append(conditions, heartbeatCondition{
+			description: "process " + name + " is running",
+			check:       func() error { return checkProcessRunning(name) },
+		})
+	}
+	for _, val := range c.StringSlice("if-command") {
+		cmd := val
+		conditions = append(conditions, heartbeatCondition{
+			description: "command [" + cmd + "] succeeds",
+			check:       func() error { return checkCommand(cmd) },
+		})
+	}
+	return conditions, nil
+}
+
+// checkHeartbeatConditions returns an error describing the first condition that does not hold.
+func checkHeartbeatConditions(conditions []heartbeatCondition) error {
+	for _, condition := range conditions {
+		printVerboseMessage("Checking condition: " + condition.description)
+		if err := condition.check(); err != nil {
+			return fmt.Errorf("condition [%s] does not hold: %s", condition.description, err.Error())
+		}
+	}
+	return nil
+}
+
+/*
+The 'JoinConditionArgs' function joins the path and the duration of
+"--if-file-newer-than PATH DURATION" given as two words into "PATH=DURATION".
+Otherwise the flag parser stops at the duration and ignores the flags after it.
+Arguments after "--" are left as they are.
+*/
+func JoinConditionArgs(args []string) []string {
+	var joined []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(joined, args[i:]...)
+		}
+		joined = append(joined, arg)
+		if arg != "--if-file-newer-than" && arg != "-if-file-newer-than" || i+2 >= len(args) {
+			continue
+		}
+		path, duration := args[i+1], args[i+2]
+		if strings.HasPrefix(path, "-") || strings.HasPrefix(duration, "-") {
+			continue
+		}
+		if _, err := time.ParseDuration(duration); err != nil {
+			continue
+		}
+		joined = append(joined, path+"="+duration)
+		i += 2
+	}
+	return joined
+}
+
+// parseFileAgeCondition splits values of the form "PATH=DURATION", "PATH DURATION" or "PATH,DURATION".
+func parseFileAgeCondition(val string) (string, time.Duration, error) {
+	val = strings.TrimSpace(val)
+	idx := strings.LastIndexAny(val, "= ,")
+	if idx <= 0 || strings.TrimSpace(val[:idx]) == "" {
+		return "", 0, errors.New("if-file-newer-than expects a path and a duration such as /backups/latest.tar=26h, but got: " + val)
+	}
+	path := strings.TrimSpace(val[:idx])
+	maxAge, err := time.ParseDuration(strings.TrimSpace(val[idx+1:]))
+	if err != nil {
+		return "", 0, errors.New("Could not parse the duration of if-file-newer-than " + val + ". " + err.Error())
+	}
+	return path, maxAge, nil
+}
+
+func checkFileNewerThan(path string, maxAge time.Duration) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if age := time.Since(info.ModTime()); age > maxAge {
+		return fmt.Errorf("file was last modified %s ago", age.Truncate(time.Second))
+	}
+	return nil
+}
+
+func checkPortOpen(address string) error {
+	conn, err := net.DialTimeout("tcp", address, conditionTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func checkHTTPOk(url string) error {
+	client := http.Client{Timeout: conditionTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New("got status " + resp.Status)
+	}
+	return nil
+}
+
+func checkProcessRunning(name string) error {
+	if runtime.GOOS == "windows" {
+		out, err := exec.Command("tasklist", "/NH", "/FI", "IMAGENAME eq "+name).Output()
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(strings.ToLower(string(out)), strings.ToLower(name)) {
+			return errors.New("no such process")
+		}
+		return nil
+	}
+	if err := exec.Command("pgrep", "-x", name).Run(); err != nil {
+		return errors.New("no such process")
+	}
+	return nil
+}
+
+func checkCommand(command string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	if verbose {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+	return cmd.Run()
+}
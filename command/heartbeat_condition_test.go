@@ -0,0 +1,122 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFileAgeCondition(t *testing.T) {
+	tests := []struct {
+		val     string
+		path    string
+		maxAge  time.Duration
+		wantErr bool
+	}{
+		{val: "/backups/latest.tar=26h", path: "/backups/latest.tar", maxAge: 26 * time.Hour},
+		{val: "/backups/latest.tar 26h", path: "/backups/latest.tar", maxAge: 26 * time.Hour},
+		{val: "/backups/latest.tar,90m", path: "/backups/latest.tar", maxAge: 90 * time.Minute},
+		{val: "/backups/my backup.tar=1h30m", path: "/backups/my backup.tar", maxAge: 90 * time.Minute},
+		{val: "/data/a=b.tar=10s", path: "/data/a=b.tar", maxAge: 10 * time.Second},
+		{val: "/backups/latest.tar", wantErr: true},
+		{val: "/backups/latest.tar=", wantErr: true},
+		{val: "/backups/latest.tar=26", wantErr: true},
+		{val: "/backups/latest.tar=yesterday", wantErr: true},
+		{val: "=26h", wantErr: true},
+		{val: "", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.val, func(t *testing.T) {
+			path, maxAge, err := parseFileAgeCondition(test.val)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("parseFileAgeCondition(%q) = %q, %s, want an error", test.val, path, maxAge)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFileAgeCondition(%q) returned %v", test.val, err)
+			}
+			if path != test.path || maxAge != test.maxAge {
+				t.Errorf("parseFileAgeCondition(%q) = %q, %s, want %q, %s", test.val, path, maxAge, test.path, test.maxAge)
+			}
+		})
+	}
+}
+
+func TestJoinConditionArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "path and duration as two words",
+			args: []string{"lamp", "heartbeat", "--name", "db-backup", "--if-file-newer-than", "/backups/latest.tar", "26h", "--if-port-open", "localhost:5432"},
+			want: []string{"lamp", "heartbeat", "--name", "db-backup", "--if-file-newer-than", "/backups/latest.tar=26h", "--if-port-open", "localhost:5432"},
+		},
+		{
+			name: "single dash flag",
+			args: []string{"lamp", "heartbeat", "-if-file-newer-than", "/a", "1h", "-if-file-newer-than", "/b", "2h"},
+			want: []string{"lamp", "heartbeat", "-if-file-newer-than", "/a=1h", "-if-file-newer-than", "/b=2h"},
+		},
+		{
+			name: "already joined",
+			args: []string{"lamp", "heartbeat", "--if-file-newer-than", "/a=1h", "--name", "db"},
+			want: []string{"lamp", "heartbeat", "--if-file-newer-than", "/a=1h", "--name", "db"},
+		},
+		{
+			name: "next word is not a duration",
+			args: []string{"lamp", "heartbeat", "--if-file-newer-than", "/a", "extra"},
+			want: []string{"lamp", "heartbeat", "--if-file-newer-than", "/a", "extra"},
+		},
+		{
+			name: "next word is a flag",
+			args: []string{"lamp", "heartbeat", "--if-file-newer-than", "/a=1h", "-v", "--name", "db"},
+			want: []string{"lamp", "heartbeat", "--if-file-newer-than", "/a=1h", "-v", "--name", "db"},
+		},
+		{
+			name: "arguments after the terminator",
+			args: []string{"lamp", "maintenance", "--", "lamp", "heartbeat", "--if-file-newer-than", "/a", "1h"},
+			want: []string{"lamp", "maintenance", "--", "lamp", "heartbeat", "--if-file-newer-than", "/a", "1h"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := JoinConditionArgs(test.args); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("JoinConditionArgs() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckFileNewerThan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "latest.tar")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		maxAge  time.Duration
+		wantErr bool
+	}{
+		{name: "within max age", path: path, maxAge: 3 * time.Hour},
+		{name: "older than max age", path: path, maxAge: time.Hour, wantErr: true},
+		{name: "missing file", path: path + ".missing", maxAge: time.Hour, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := checkFileNewerThan(test.path, test.maxAge); (err != nil) != test.wantErr {
+				t.Errorf("checkFileNewerThan() returned %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package command
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/opsgenie/opsgenie-go-sdk/schedulev2"
+)
+
+const icsTimeLayout = "20060102T150405Z"
+
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// writeICS writes the shifts of the schedule as an iCalendar file with one VEVENT per shift.
+// The UID of an event is derived from the schedule, the rotation and the start of the timeline period,
+// so exporting the same shift again yields the same UID and calendars update the event instead of duplicating it.
+func writeICS(w io.Writer, schedule schedulev2.Schedule, shifts shiftList, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	write := func(name string, value string) {
+		writeICSLine(bw, name+":"+value)
+	}
+
+	write("BEGIN", "VCALENDAR")
+	write("VERSION", "2.0")
+	write("PRODID", "-//OpsGenie//lamp//EN")
+	write("CALSCALE", "GREGORIAN")
+	write("METHOD", "PUBLISH")
+	write("X-WR-CALNAME", escapeICSText(schedule.Name))
+	if schedule.Timezone != "" {
+		write("X-WR-TIMEZONE", escapeICSText(schedule.Timezone))
+	}
+	for _, s := range shifts {
+		write("BEGIN", "VEVENT")
+		write("UID", shiftUID(schedule, s))
+		write("DTSTAMP", now.UTC().Format(icsTimeLayout))
+		write("DTSTART", s.StartDate.UTC().Format(icsTimeLayout))
+		write("DTEND", s.EndDate.UTC().Format(icsTimeLayout))
+		write("SUMMARY", escapeICSText("On call: "+s.Participant+" ("+s.Rotation+")"))
+		write("DESCRIPTION", escapeICSText("Schedule: "+schedule.Name+"\nRotation: "+s.Rotation+
+			"\nParticipant: "+s.Participant))
+		write("CATEGORIES", "On call")
+		write("TRANSP", "TRANSPARENT")
+		write("END", "VEVENT")
+	}
+	write("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+func shiftUID(schedule schedulev2.Schedule, s shift) string {
+	rotation := s.RotationID
+	if rotation == "" {
+		rotation = s.Rotation
+	}
+	start := s.periodStart
+	if start.IsZero() {
+		start = s.StartDate
+	}
+	sum := sha1.Sum([]byte(schedule.ID + "|" + rotation + "|" + start.UTC().Format(time.RFC3339)))
+	return hex.EncodeToString(sum[:]) + "@lamp.opsgenie.com"
+}
+
+func escapeICSText(text string) string {
+	return icsTextEscaper.Replace(text)
+}
+
+// writeICSLine writes a content line terminated by CRLF, folding it into lines of at most 75 octets
+// without splitting multi-byte characters.
+func writeICSLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space, which counts towards the limit
+		limit = 74
+	}
+	w.WriteString(line + "\r\n")
+}
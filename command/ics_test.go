@@ -0,0 +1,155 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/opsgenie/opsgenie-go-sdk/schedulev2"
+)
+
+func TestEscapeICSText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "On call", want: "On call"},
+		{text: "Ops; DB, Web", want: `Ops\; DB\, Web`},
+		{text: `C:\backup`, want: `C:\\backup`},
+		{text: "first\nsecond", want: `first\nsecond`},
+		{text: "first\r\nsecond", want: `first\nsecond`},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			if got := escapeICSText(test.text); got != test.want {
+				t.Errorf("escapeICSText(%q) = %q, want %q", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestWriteICSLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{name: "short", line: "SUMMARY:On call", want: "SUMMARY:On call\r\n"},
+		{name: "exactly 75 octets", line: strings.Repeat("a", 75), want: strings.Repeat("a", 75) + "\r\n"},
+		{
+			name: "folded",
+			line: strings.Repeat("a", 160),
+			want: strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n " + strings.Repeat("a", 11) + "\r\n",
+		},
+		{
+			name: "multi-byte character is not split",
+			line: strings.Repeat("a", 74) + "ğb",
+			want: strings.Repeat("a", 74) + "\r\n ğb\r\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := bufio.NewWriter(&buf)
+			writeICSLine(w, test.line)
+			w.Flush()
+			if got := buf.String(); got != test.want {
+				t.Errorf("writeICSLine() wrote %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	schedule := schedulev2.Schedule{ID: "s1", Name: "Ops, primary", Timezone: "Europe/Istanbul"}
+	start := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	shifts := shiftList{
+		{Rotation: "Weekly", RotationID: "r1", Participant: "jane@example.com", StartDate: start, EndDate: start.Add(24 * time.Hour)},
+		{Rotation: "Weekly", RotationID: "r1", Participant: "john@example.com" + strings.Repeat("x", 60),
+			StartDate: start.Add(24 * time.Hour), EndDate: start.Add(48 * time.Hour)},
+	}
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := writeICS(&buf, schedule, shifts, now); err != nil {
+		t.Fatal(err)
+	}
+	content := buf.String()
+	if !strings.HasSuffix(content, "END:VCALENDAR\r\n") {
+		t.Errorf("content does not end with END:VCALENDAR and CRLF")
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\r\n"), "\r\n")
+	for _, line := range lines {
+		if strings.Contains(line, "\n") {
+			t.Errorf("line %q is not terminated by CRLF", line)
+		}
+		if len(line) > 75 || !utf8.ValidString(line) {
+			t.Errorf("line %q is not folded at 75 octets", line)
+		}
+	}
+	for _, want := range []string{
+		"BEGIN:VCALENDAR",
+		`X-WR-CALNAME:Ops\, primary`,
+		"X-WR-TIMEZONE:Europe/Istanbul",
+		"DTSTAMP:20261019T080000Z",
+		"DTSTART:20261019T090000Z",
+		"DTEND:20261020T090000Z",
+		`SUMMARY:On call: jane@example.com (Weekly)`,
+	} {
+		if !containsLine(lines, want) {
+			t.Errorf("content does not contain the line %q", want)
+		}
+	}
+	if got := strings.Count(content, "BEGIN:VEVENT\r\n"); got != len(shifts) {
+		t.Errorf("content has %d events, want %d", got, len(shifts))
+	}
+}
+
+func TestShiftUID(t *testing.T) {
+	schedule := schedulev2.Schedule{ID: "s1"}
+	start := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	s := shift{Rotation: "Weekly", RotationID: "r1", StartDate: start, EndDate: start.Add(time.Hour)}
+
+	uid := shiftUID(schedule, s)
+	if !strings.HasSuffix(uid, "@lamp.opsgenie.com") {
+		t.Errorf("shiftUID() = %q, want a lamp.opsgenie.com UID", uid)
+	}
+
+	clipped := s
+	clipped.periodStart = start
+	clipped.StartDate = start.Add(30 * time.Minute)
+	renamed := s
+	renamed.Rotation = "Renamed"
+	otherParticipant := s
+	otherParticipant.Participant = "john@example.com"
+	for name, other := range map[string]shift{"clipped": clipped, "renamed rotation": renamed, "other participant": otherParticipant} {
+		if got := shiftUID(schedule, other); got != uid {
+			t.Errorf("%s: shiftUID() = %q, want %q", name, got, uid)
+		}
+	}
+
+	later := s
+	later.StartDate = start.Add(24 * time.Hour)
+	otherRotation := s
+	otherRotation.RotationID = "r2"
+	for name, other := range map[string]shift{"later": later, "other rotation": otherRotation} {
+		if got := shiftUID(schedule, other); got == uid {
+			t.Errorf("%s: shiftUID() = %q, want a different UID", name, got)
+		}
+	}
+	if got := shiftUID(schedulev2.Schedule{ID: "s2"}, s); got == uid {
+		t.Errorf("other schedule: shiftUID() = %q, want a different UID", got)
+	}
+}
+
+func containsLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
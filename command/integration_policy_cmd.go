@@ -1,114 +1,647 @@
 package command
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	gcli "github.com/codegangsta/cli"
+	ogcli "github.com/opsgenie/opsgenie-go-sdk/client"
 	"github.com/opsgenie/opsgenie-go-sdk/integration"
 	"github.com/opsgenie/opsgenie-go-sdk/policy"
+	"github.com/opsgenie/opsgenie-lamp/cfg"
 )
 
-// EnableAction enables an integration/policy according to the --type parameter at OpsGenie.
+// EnableAction enables the integrations/policies according to the --type parameter at OpsGenie.
 func EnableAction(c *gcli.Context) {
-	val, _ := getVal("type", c)
-	switch val {
-	case "policy":
-		cli, err := NewPolicyClient(c)
-		if err != nil {
-			os.Exit(1)
+	toggleAction(c, true)
+}
+
+// DisableAction disables the integrations/policies according to the --type parameter at OpsGenie.
+// When --for or --until is given, they are recorded to be re-enabled by the reconcile command.
+func DisableAction(c *gcli.Context) {
+	toggleAction(c, false)
+}
+
+// toggleTarget is an integration/policy selected with the --id, --name, --name-regex or --from-file parameters.
+type toggleTarget struct {
+	ID   string
+	Name string
+}
+
+func (t toggleTarget) String() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.ID
+}
+
+func toggleAction(c *gcli.Context, enabled bool) {
+	commandName := strings.ToLower(toggleVerb(enabled))
+	kind, _ := getVal("type", c)
+	if kind != "integration" && kind != "policy" {
+		fmt.Printf("Invalid type option %s, specify either integration or policy\n", kind)
+		gcli.ShowCommandHelp(c, commandName)
+		os.Exit(1)
+	}
+	var enableAt time.Time
+	timed := false
+	if !enabled {
+		enableAt, timed = grabReEnableTime(c)
+	}
+	dryRun := c.IsSet("dry-run")
+
+	t := &toggler{c: c}
+	targets, err := collectToggleTargets(c, t, kind)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	if len(targets) == 0 {
+		fmt.Printf("Either id, name, name-regex or from-file must be provided and match at least one %s\n", kind)
+		gcli.ShowCommandHelp(c, commandName)
+		os.Exit(1)
+	}
+
+	failed := false
+	var scheduled []string
+	for _, target := range targets {
+		if dryRun {
+			fmt.Printf("%s %s would be %sd\n", capitalize(kind), target, commandName)
+			continue
 		}
+		if err := t.setEnabled(kind, target.ID, target.Name, enabled); err != nil {
+			fmt.Printf("%s %s could not be %sd: %s\n", capitalize(kind), target, commandName, err.Error())
+			failed = true
+			continue
+		}
+		fmt.Printf("%s %s %sd successfuly\n", capitalize(kind), target, commandName)
 
-		req := policy.EnablePolicyRequest{}
-		if val, success := getVal("id", c); success {
-			req.ID = val
+		// the id is looked up only when a re-enable is stored or one may be stored by id already
+		id := target.ID
+		if id == "" && (timed || hasPendingEnableNamed(kind, target.Name)) {
+			if id, err = t.resolveID(kind, target.Name); err != nil {
+				printWarningMessage("WARNING: Could not find the id of " + target.Name + ", its scheduled re-enable is matched by name: " + err.Error())
+			}
 		}
-		if val, success := getVal("name", c); success {
-			req.Name = val
+		if timed {
+			entry := newPendingEnable(c, enableAt)
+			entry.Type, entry.ID, entry.Name = kind, id, target.Name
+			if err := schedulePendingEnable(entry); err != nil {
+				fmt.Printf("Could not record the re-enable of %s: %s\n", entry.describe(), err.Error())
+				failed = true
+			} else {
+				scheduled = append(scheduled, target.String())
+			}
+		} else if err := removePendingEnable(kind, id, target.Name); err != nil {
+			printWarningMessage("WARNING: Could not remove the scheduled re-enable: " + err.Error())
 		}
-		printVerboseMessage("Enable policy request prepared from flags, sending request to OpsGenie..")
-		_, err = cli.Enable(req)
+	}
+	if len(scheduled) > 0 {
+		fmt.Printf("%s will be re-enabled by 'lamp reconcile' after %s\n", strings.Join(scheduled, ", "), enableAt.Format(time.RFC3339))
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// collectToggleTargets gathers the integrations/policies given by id, by name, by a name pattern and in a file, without duplicates.
+func collectToggleTargets(c *gcli.Context, t *toggler, kind string) ([]toggleTarget, error) {
+	var targets []toggleTarget
+	seen := map[toggleTarget]bool{}
+	add := func(target toggleTarget) {
+		if !seen[target] {
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+
+	ids, names := c.StringSlice("id"), c.StringSlice("name")
+	for _, id := range ids {
+		isEmpty("id", id, c)
+	}
+	for _, name := range names {
+		isEmpty("name", name, c)
+	}
+	if len(ids) == 1 && len(names) == 1 {
+		// a single id and name identify one integration/policy, as they always did
+		add(toggleTarget{ID: ids[0], Name: names[0]})
+	} else {
+		for _, id := range ids {
+			add(toggleTarget{ID: id})
+		}
+		for _, name := range names {
+			add(toggleTarget{Name: name})
+		}
+	}
+	if val, success := getVal("from-file", c); success {
+		names, err := readNamesFile(val)
 		if err != nil {
-			fmt.Printf("%s\n", err.Error())
-			os.Exit(1)
+			return nil, err
+		}
+		for _, name := range names {
+			add(toggleTarget{Name: name})
+		}
+	}
+	if val, success := getVal("name-regex", c); success {
+		pattern, err := regexp.Compile(val)
+		if err != nil {
+			return nil, errors.New("Invalid name-regex " + val + ". " + err.Error())
 		}
-		fmt.Printf("Policy enabled successfuly\n")
+		names, err := t.names(kind)
+		if err != nil {
+			return nil, err
+		}
+		matched := 0
+		for _, name := range names {
+			if pattern.MatchString(name) {
+				add(toggleTarget{Name: name})
+				matched++
+			}
+		}
+		printVerboseMessage(strconv.Itoa(matched) + " " + kind + " names matched " + val)
+	}
+	return targets, nil
+}
 
-	case "integration":
-		cli, err := NewIntegrationClient(c)
+// readNamesFile reads one name per line, skipping blank lines and lines starting with #.
+func readNamesFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("Could not read names from " + path + ". " + err.Error())
+	}
+	defer file.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("Could not read names from " + path + ". " + err.Error())
+	}
+	return names, nil
+}
+
+// ReconcileAction re-enables the integrations/policies whose disable window given with --for or --until has expired.
+// Each one is re-enabled with the profile and at the endpoint it was disabled with.
+func ReconcileAction(c *gcli.Context) {
+	if c.IsSet("v") {
+		verbose = true
+	}
+	entries, err := loadPendingEnables()
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	var remaining []pendingEnable
+	var expired []pendingEnable
+	for _, entry := range entries {
+		if entry.EnableAt.After(now) {
+			printVerboseMessage(entry.describe() + " will stay disabled until " + entry.EnableAt.Format(time.RFC3339))
+			remaining = append(remaining, entry)
+		} else {
+			expired = append(expired, entry)
+		}
+	}
+	if len(expired) == 0 {
+		fmt.Printf("Nothing to re-enable\n")
+		return
+	}
+
+	readConfigFile(c)
+	defer useRecordedAccount(pendingEnable{})
+	togglers := map[string]*toggler{}
+	failed := false
+	for _, entry := range expired {
+		t, err := recordedAccountToggler(c, togglers, entry)
+		if err == nil {
+			name := entry.Name
+			if entry.ID != "" {
+				name = ""
+			}
+			err = t.setEnabled(entry.Type, entry.ID, name, true)
+		}
 		if err != nil {
-			os.Exit(1)
+			fmt.Printf("Could not re-enable %s: %s\n", entry.describe(), err.Error())
+			remaining = append(remaining, entry)
+			failed = true
+			continue
 		}
+		fmt.Printf("Re-enabled %s\n", entry.describe())
+	}
+
+	if err := savePendingEnables(remaining); err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
 
-		req := integration.EnableIntegrationRequest{}
-		if val, success := getVal("id", c); success {
-			req.ID = val
+// recordedAccountToggler selects the profile and the endpoint the entry was recorded with, and returns the toggler
+// of that account. The togglers are kept in the map so that the clients of each account are created once.
+func recordedAccountToggler(c *gcli.Context, togglers map[string]*toggler, entry pendingEnable) (*toggler, error) {
+	if entry.Profile != "" {
+		if err := cfg.SelectProfile(entry.Profile); err != nil {
+			return nil, err
 		}
-		if val, success := getVal("name", c); success {
-			req.Name = val
+	}
+	useRecordedAccount(entry)
+	key := entry.Profile + " " + entry.Endpoint
+	if t, found := togglers[key]; found {
+		return t, nil
+	}
+	printVerboseMessage("Re-enabling with profile " + cfg.ActiveProfile() + "..")
+	t := &toggler{c: c}
+	togglers[key] = t
+	return t, nil
+}
+
+// grabReEnableTime returns the time given with --for or --until, after which a disabled integration/policy is re-enabled.
+func grabReEnableTime(c *gcli.Context) (time.Time, bool) {
+	if val, success := getVal("for", c); success {
+		duration, err := time.ParseDuration(val)
+		if err != nil || duration <= 0 {
+			fmt.Printf("Invalid duration %s, use a value such as 30m or 2h\n", val)
+			os.Exit(1)
 		}
-		printVerboseMessage("Enable integration request prepared from flags, sending request to OpsGenie..")
-		_, err = cli.Enable(req)
+		return time.Now().Add(duration), true
+	}
+	if val, success := getVal("until", c); success {
+		until, err := time.Parse(time.RFC3339, val)
 		if err != nil {
 			fmt.Printf("%s\n", err.Error())
 			os.Exit(1)
 		}
-		fmt.Printf("Integration enabled successfuly\n")
-	default:
-		fmt.Printf("Invalid type option %s, specify either integration or policy\n", val)
-		gcli.ShowCommandHelp(c, "enable")
-		os.Exit(1)
+		return until, true
 	}
+	return time.Time{}, false
 }
 
-// DisableAction disables an integration/policy according to the --type parameter at OpsGenie.
-func DisableAction(c *gcli.Context) {
-	val, _ := getVal("type", c)
-	switch val {
-	case "policy":
-		cli, err := NewPolicyClient(c)
+// toggler enables and disables integrations and policies, creating the clients on first use.
+type toggler struct {
+	c      *gcli.Context
+	intCli *ogcli.OpsGenieIntegrationClient
+	polCli *ogcli.OpsGeniePolicyClient
+	rest   *restClient
+	// listed holds the integrations/policies of each kind once they are listed
+	listed map[string][]toggleTarget
+}
+
+func (t *toggler) integrationClient() (*ogcli.OpsGenieIntegrationClient, error) {
+	if t.intCli == nil {
+		cli, err := NewIntegrationClient(t.c)
 		if err != nil {
-			os.Exit(1)
+			return nil, err
+		}
+		t.intCli = cli
+	}
+	return t.intCli, nil
+}
+
+func (t *toggler) policyClient() (*ogcli.OpsGeniePolicyClient, error) {
+	if t.polCli == nil {
+		cli, err := NewPolicyClient(t.c)
+		if err != nil {
+			return nil, err
 		}
+		t.polCli = cli
+	}
+	return t.polCli, nil
+}
 
-		req := policy.DisablePolicyRequest{}
-		if val, success := getVal("id", c); success {
-			req.ID = val
+func (t *toggler) restClient() *restClient {
+	if t.rest == nil {
+		t.rest = newRESTClient(t.c)
+	}
+	return t.rest
+}
+
+// setEnabled enables or disables the integration/policy identified by id or name.
+func (t *toggler) setEnabled(kind string, id string, name string, enabled bool) error {
+	switch kind {
+	case "integration":
+		cli, err := t.integrationClient()
+		if err != nil {
+			return err
 		}
-		if val, success := getVal("name", c); success {
-			req.Name = val
+		printVerboseMessage(toggleVerb(enabled) + " integration request prepared, sending request to OpsGenie..")
+		if enabled {
+			_, err = cli.Enable(integration.EnableIntegrationRequest{ID: id, Name: name})
+		} else {
+			_, err = cli.Disable(integration.DisableIntegrationRequest{ID: id, Name: name})
 		}
-		printVerboseMessage("Disable policy request prepared from flags, sending request to OpsGenie..")
-		_, err = cli.Disable(req)
+		return err
+	case "policy":
+		cli, err := t.policyClient()
 		if err != nil {
-			fmt.Printf("%s\n", err.Error())
-			os.Exit(1)
+			return err
 		}
-		fmt.Printf("Policy disabled successfuly\n")
+		printVerboseMessage(toggleVerb(enabled) + " policy request prepared, sending request to OpsGenie..")
+		if enabled {
+			_, err = cli.Enable(policy.EnablePolicyRequest{ID: id, Name: name})
+		} else {
+			_, err = cli.Disable(policy.DisablePolicyRequest{ID: id, Name: name})
+		}
+		return err
+	}
+	return errors.New("Invalid type option " + kind + ", specify either integration or policy")
+}
 
+// list returns the ids and names of all integrations/policies of the given kind, listing them once.
+func (t *toggler) list(kind string) ([]toggleTarget, error) {
+	if targets, found := t.listed[kind]; found {
+		return targets, nil
+	}
+	var targets []toggleTarget
+	switch kind {
 	case "integration":
-		cli, err := NewIntegrationClient(c)
+		integrations, err := t.restClient().listIntegrations("", "")
 		if err != nil {
-			os.Exit(1)
+			return nil, err
 		}
+		for _, i := range integrations {
+			targets = append(targets, toggleTarget{ID: i.ID, Name: i.Name})
+		}
+	case "policy":
+		policies, err := t.restClient().listPolicies("")
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range policies {
+			targets = append(targets, toggleTarget{ID: p.ID, Name: p.Name})
+		}
+	}
+	if t.listed == nil {
+		t.listed = map[string][]toggleTarget{}
+	}
+	t.listed[kind] = targets
+	return targets, nil
+}
+
+// names lists the names of all integrations/policies of the given kind.
+func (t *toggler) names(kind string) ([]string, error) {
+	targets, err := t.list(kind)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+	return names, nil
+}
 
-		req := integration.DisableIntegrationRequest{}
-		if val, success := getVal("id", c); success {
-			req.ID = val
+// resolveID returns the id of the integration/policy of the given kind with the given name.
+func (t *toggler) resolveID(kind string, name string) (string, error) {
+	targets, err := t.list(kind)
+	if err != nil {
+		return "", err
+	}
+	for _, target := range targets {
+		if target.Name == name {
+			return target.ID, nil
 		}
-		if val, success := getVal("name", c); success {
-			req.Name = val
+	}
+	return "", errors.New("no " + kind + " is named " + name)
+}
+
+// capitalize upper-cases the first letter of a kind such as "integration" to start a message with it.
+func capitalize(kind string) string {
+	if kind == "" {
+		return kind
+	}
+	return strings.ToUpper(kind[:1]) + kind[1:]
+}
+
+func toggleVerb(enabled bool) string {
+	if enabled {
+		return "Enable"
+	}
+	return "Disable"
+}
+
+// ListIntegrationsAction retrieves the integrations defined at OpsGenie.
+func ListIntegrationsAction(c *gcli.Context) {
+	cli := newRESTClient(c)
+
+	integrationType, _ := getVal("integrationType", c)
+	teamName, _ := getVal("teamName", c)
+	printVerboseMessage("List integrations request prepared from flags, sending request to OpsGenie..")
+
+	integrations, err := cli.listIntegrations(integrationType, teamName)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Integrations listed successfully.")
+	printResult(c, integrationList(integrations))
+}
+
+// GetIntegrationAction retrieves the definition of an integration from OpsGenie.
+func GetIntegrationAction(c *gcli.Context) {
+	cli := newRESTClient(c)
+
+	id, _ := getVal("id", c)
+	if val, success := getVal("name", c); success && id == "" {
+		found, err := findIntegration(cli, val)
+		if err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
 		}
-		printVerboseMessage("Disable integration request prepared from flags, sending request to OpsGenie..")
-		_, err = cli.Disable(req)
+		id = found.ID
+	}
+	if id == "" {
+		fmt.Printf("Either id or name of the integration must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	printVerboseMessage("Get integration request prepared from flags, sending request to OpsGenie..")
+
+	definition, err := cli.getIntegration(id)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Got integration successfully.")
+	printResult(c, definition)
+}
+
+// ListPoliciesAction retrieves the policies defined at OpsGenie.
+func ListPoliciesAction(c *gcli.Context) {
+	cli := newRESTClient(c)
+
+	teamID, _ := getVal("teamId", c)
+	printVerboseMessage("List policies request prepared from flags, sending request to OpsGenie..")
+
+	policies, err := cli.listPolicies(teamID)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Policies listed successfully.")
+	printResult(c, policyList(policies))
+}
+
+// GetPolicyAction retrieves the definition of a policy from OpsGenie.
+func GetPolicyAction(c *gcli.Context) {
+	cli := newRESTClient(c)
+
+	teamID, _ := getVal("teamId", c)
+	id, _ := getVal("id", c)
+	if val, success := getVal("name", c); success && id == "" {
+		found, err := findPolicy(cli, teamID, val)
 		if err != nil {
 			fmt.Printf("%s\n", err.Error())
 			os.Exit(1)
 		}
-		fmt.Printf("Integration disabled successfuly\n")
-	default:
-		fmt.Printf("Invalid type option %s, specify either integration or policy\n", val)
-		gcli.ShowCommandHelp(c, "disable")
+		id = found.ID
+	}
+	if id == "" {
+		fmt.Printf("Either id or name of the policy must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
 		os.Exit(1)
 	}
+	printVerboseMessage("Get policy request prepared from flags, sending request to OpsGenie..")
+
+	definition, err := cli.getPolicy(id, teamID)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Got policy successfully.")
+	printResult(c, definition)
+}
+
+// integrationInfo is an integration as listed by the OpsGenie REST API.
+type integrationInfo struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+	TeamID  string `json:"teamId,omitempty"`
+}
+
+// policyInfo is a policy as listed by the OpsGenie REST API.
+type policyInfo struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+	Order   int    `json:"order"`
+}
+
+// listIntegrations lists the integrations, only the ones of the given type and team when they are not empty.
+func (r *restClient) listIntegrations(integrationType string, teamName string) ([]integrationInfo, error) {
+	query := url.Values{}
+	if integrationType != "" {
+		query.Set("type", integrationType)
+	}
+	if teamName != "" {
+		query.Set("teamName", teamName)
+	}
+	var integrations []integrationInfo
+	err := r.do("GET", "/v2/integrations", query, nil, &integrations)
+	return integrations, err
+}
+
+// getIntegration returns the whole definition of the integration.
+func (r *restClient) getIntegration(id string) (map[string]interface{}, error) {
+	var definition map[string]interface{}
+	err := r.do("GET", "/v2/integrations/"+url.PathEscape(id), nil, nil, &definition)
+	return definition, err
+}
+
+// listPolicies lists the alert policies of the team, or the global ones when teamID is empty.
+func (r *restClient) listPolicies(teamID string) ([]policyInfo, error) {
+	var policies []policyInfo
+	err := r.do("GET", "/v2/policies/alert", teamQuery(teamID), nil, &policies)
+	return policies, err
+}
+
+// getPolicy returns the whole definition of the policy.
+func (r *restClient) getPolicy(id string, teamID string) (map[string]interface{}, error) {
+	var definition map[string]interface{}
+	err := r.do("GET", "/v2/policies/"+url.PathEscape(id), teamQuery(teamID), nil, &definition)
+	return definition, err
+}
+
+func teamQuery(teamID string) url.Values {
+	query := url.Values{}
+	if teamID != "" {
+		query.Set("teamId", teamID)
+	}
+	return query
+}
+
+// findIntegration looks up the integration with the given name.
+func findIntegration(cli *restClient, name string) (*integrationInfo, error) {
+	integrations, err := cli.listIntegrations("", "")
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range integrations {
+		if i.Name == name {
+			return &i, nil
+		}
+	}
+	return nil, errors.New("Could not find an integration named " + name)
+}
+
+// findPolicy looks up the policy with the given name.
+func findPolicy(cli *restClient, teamID string, name string) (*policyInfo, error) {
+	policies, err := cli.listPolicies(teamID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range policies {
+		if p.Name == name {
+			return &p, nil
+		}
+	}
+	return nil, errors.New("Could not find a policy named " + name)
+}
+
+// integrationList prints integrations with their state in table output format.
+type integrationList []integrationInfo
+
+func (l integrationList) tableHeaders() []string {
+	return []string{"id", "name", "type", "enabled"}
+}
+
+func (l integrationList) tableRows() [][]string {
+	var rows [][]string
+	for _, i := range l {
+		rows = append(rows, []string{i.ID, i.Name, i.Type, strconv.FormatBool(i.Enabled)})
+	}
+	return rows
+}
+
+// policyList prints policies with their state in table output format.
+type policyList []policyInfo
+
+func (l policyList) tableHeaders() []string {
+	return []string{"id", "name", "type", "enabled", "order"}
+}
+
+func (l policyList) tableRows() [][]string {
+	var rows [][]string
+	for _, p := range l {
+		rows = append(rows, []string{p.ID, p.Name, p.Type, strconv.FormatBool(p.Enabled), strconv.Itoa(p.Order)})
+	}
+	return rows
 }
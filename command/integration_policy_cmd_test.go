@@ -0,0 +1,203 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/opsgenie/opsgenie-lamp/cfg"
+)
+
+func TestIntegrationListTableRows(t *testing.T) {
+	tests := []struct {
+		name         string
+		integrations integrationList
+		want         [][]string
+	}{
+		{
+			name:         "empty",
+			integrations: integrationList{},
+			want:         nil,
+		},
+		{
+			name: "enabled and disabled",
+			integrations: integrationList{
+				{ID: "i1", Name: "Datadog", Type: "Datadog", Enabled: true},
+				{ID: "i2", Name: "Mail", Type: "Email"},
+			},
+			want: [][]string{
+				{"i1", "Datadog", "Datadog", "true"},
+				{"i2", "Mail", "Email", "false"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.integrations.tableRows(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("tableRows() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPolicyListTableRows(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies policyList
+		want     [][]string
+	}{
+		{
+			name:     "empty",
+			policies: policyList{},
+			want:     nil,
+		},
+		{
+			name: "ordered policies",
+			policies: policyList{
+				{ID: "p1", Name: "Night", Type: "alert", Enabled: true, Order: 1},
+				{ID: "p2", Name: "Weekend", Type: "alert", Order: 2},
+			},
+			want: [][]string{
+				{"p1", "Night", "alert", "true", "1"},
+				{"p2", "Weekend", "alert", "false", "2"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.policies.tableRows(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("tableRows() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIntegrationAndPolicyTables(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		want string
+	}{
+		{
+			name: "integrations",
+			data: integrationList{integrationInfo{ID: "i1", Name: "Datadog", Type: "Datadog", Enabled: true}},
+			want: "ID  NAME     TYPE     ENABLED\n" +
+				"i1  Datadog  Datadog  true",
+		},
+		{
+			name: "policies",
+			data: policyList{policyInfo{ID: "p1", Name: "Night", Type: "alert", Order: 3}},
+			want: "ID  NAME   TYPE   ENABLED  ORDER\n" +
+				"p1  Night  alert  false    3",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := resultToTable(test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("resultToTable() =\n%q\nwant\n%q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCollectToggleTargets(t *testing.T) {
+	namesFile := filepath.Join(t.TempDir(), "names.txt")
+	if err := os.WriteFile(namesFile, []byte("# muted during deploys\nweb\n\n  api  \nweb\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		flags  map[string]string
+		slices map[string][]string
+		want   []toggleTarget
+	}{
+		{
+			name:   "single id and name are one target",
+			slices: map[string][]string{"id": {"1"}, "name": {"web"}},
+			want:   []toggleTarget{{ID: "1", Name: "web"}},
+		},
+		{
+			name:   "repeated ids and names",
+			slices: map[string][]string{"id": {"1", "2"}, "name": {"web"}},
+			want:   []toggleTarget{{ID: "1"}, {ID: "2"}, {Name: "web"}},
+		},
+		{
+			name:   "duplicates are dropped",
+			slices: map[string][]string{"name": {"web", "api", "web"}},
+			want:   []toggleTarget{{Name: "web"}, {Name: "api"}},
+		},
+		{
+			name:   "names file",
+			flags:  map[string]string{"from-file": namesFile},
+			slices: map[string][]string{"name": {"db"}},
+			want:   []toggleTarget{{Name: "db"}, {Name: "web"}, {Name: "api"}},
+		},
+		{
+			name: "nothing given",
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestSliceContext(t, test.flags, test.slices)
+			got, err := collectToggleTargets(c, &toggler{c: c}, "integration")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("collectToggleTargets() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResolveID(t *testing.T) {
+	tg := &toggler{listed: map[string][]toggleTarget{
+		"integration": {{ID: "1", Name: "web"}, {ID: "2", Name: "api"}},
+	}}
+	if id, err := tg.resolveID("integration", "api"); err != nil || id != "2" {
+		t.Errorf("resolveID(api) = %q, %v, want 2", id, err)
+	}
+	if _, err := tg.resolveID("integration", "db"); err == nil {
+		t.Errorf("resolveID(db) returned no error")
+	}
+}
+
+func TestRecordedAccountToggler(t *testing.T) {
+	selectTestProfile(t, "")
+	defer useRecordedAccount(pendingEnable{})
+	c := newTestContext(t, nil)
+	togglers := map[string]*toggler{}
+
+	staging, err := recordedAccountToggler(c, togglers, pendingEnable{Profile: "staging", Endpoint: "https://api.eu.opsgenie.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.ActiveProfile(); got != "staging" {
+		t.Errorf("ActiveProfile() = %q, want staging", got)
+	}
+	if got := resolveEndpoint(c).URL; got != "https://api.eu.opsgenie.com" {
+		t.Errorf("resolveEndpoint() = %q, want the recorded endpoint", got)
+	}
+	if again, _ := recordedAccountToggler(c, togglers, pendingEnable{Profile: "staging", Endpoint: "https://api.eu.opsgenie.com"}); again != staging {
+		t.Error("recordedAccountToggler() returned another toggler for the same account")
+	}
+
+	prod, err := recordedAccountToggler(c, togglers, pendingEnable{Profile: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prod == staging || cfg.ActiveProfile() != "prod" || recordedEndpoint != "" {
+		t.Errorf("recordedAccountToggler() kept the account of the previous entry, profile %s endpoint %q", cfg.ActiveProfile(), recordedEndpoint)
+	}
+
+	if _, err := recordedAccountToggler(c, togglers, pendingEnable{Profile: "removed"}); err == nil {
+		t.Error("recordedAccountToggler() returned no error for an undefined profile")
+	}
+}
@@ -0,0 +1,257 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	gcli "github.com/codegangsta/cli"
+	log "github.com/opsgenie/opsgenie-go-sdk/logging"
+	"github.com/opsgenie/opsgenie-lamp/cfg"
+)
+
+const maintenanceLogFile = "maintenance.log"
+
+// maintenanceTarget is an integration/policy muted during a maintenance run, with the state it is restored to.
+type maintenanceTarget struct {
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	WasEnabled bool   `json:"wasEnabled"`
+	Disabled   bool   `json:"disabled"`
+	Restored   bool   `json:"restored"`
+	Error      string `json:"error,omitempty"`
+}
+
+// maintenanceRecord is appended to the maintenance log for every maintenance run.
+type maintenanceRecord struct {
+	StartedAt  time.Time           `json:"startedAt"`
+	FinishedAt time.Time           `json:"finishedAt"`
+	User       string              `json:"user,omitempty"`
+	Command    []string            `json:"command"`
+	ExitCode   int                 `json:"exitCode"`
+	Signal     string              `json:"signal,omitempty"`
+	Targets    []maintenanceTarget `json:"targets"`
+}
+
+// MaintenanceAction disables the given integrations and policies, runs the command given after "--"
+// and restores their original enabled/disabled state afterwards, even if the command fails or is interrupted.
+func MaintenanceAction(c *gcli.Context) {
+	args := c.Args()
+	if len(args) == 0 {
+		fmt.Printf("The command to run must be given after --\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	integrations := c.StringSlice("integration")
+	policies := c.StringSlice("policy")
+	if len(integrations) == 0 && len(policies) == 0 {
+		fmt.Printf("At least one integration or policy must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	maxDuration := 6 * time.Hour
+	if val, success := getVal("max-duration", c); success {
+		duration, err := time.ParseDuration(val)
+		if err != nil || duration <= 0 {
+			fmt.Printf("Invalid duration %s, use a value such as 30m or 2h\n", val)
+			os.Exit(1)
+		}
+		maxDuration = duration
+	}
+
+	t := &toggler{c: c}
+	record := maintenanceRecord{StartedAt: time.Now(), Command: args, User: grabUsername(c)}
+	targets, err := resolveMaintenanceTargets(t, integrations, policies)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	// Catch the signals before muting anything so that an interrupt can not skip the restore.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	runMaintenance(t, &record, targets, newPendingEnable(c, time.Now().Add(maxDuration)), signals)
+	record.FinishedAt = time.Now()
+	writeMaintenanceRecord(record)
+
+	for _, target := range targets {
+		if target.Error != "" {
+			os.Exit(1)
+		}
+	}
+	if record.ExitCode != 0 {
+		os.Exit(record.ExitCode)
+	}
+}
+
+// enabler enables and disables integrations and policies, as the toggler does at OpsGenie.
+type enabler interface {
+	setEnabled(kind string, id string, name string, enabled bool) error
+}
+
+// runMaintenance mutes the targets, runs the command of the record and restores the targets afterwards,
+// recording its exit code and the signal received. The command is not started if a signal arrives while muting.
+// Each muted target is recorded like reEnable to be re-enabled by the reconcile command.
+func runMaintenance(e enabler, record *maintenanceRecord, targets []maintenanceTarget, reEnable pendingEnable, signals chan os.Signal) {
+	args := record.Command
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if !muteMaintenanceTargets(e, targets, reEnable) {
+		fmt.Printf("Not running the maintenance command since muting failed\n")
+		record.ExitCode = 1
+	} else if sig := receivedSignal(signals); sig != nil {
+		record.Signal = sig.String()
+		fmt.Printf("Not running the maintenance command since %s was received\n", sig.String())
+		record.ExitCode = 1
+	} else if err := cmd.Start(); err != nil {
+		fmt.Printf("Could not run the maintenance command: %s\n", err.Error())
+		record.ExitCode = 1
+	} else {
+		done := make(chan error, 1)
+		printVerboseMessage("Running maintenance command: " + strings.Join(args, " "))
+		go func() { done <- cmd.Wait() }()
+	wait:
+		for {
+			select {
+			case sig := <-signals:
+				record.Signal = sig.String()
+				printWarningMessage("Received " + sig.String() + ", waiting for the maintenance command to exit before restoring..")
+				// An interrupt from the terminal already reaches the whole process group, only a termination is forwarded.
+				if sig == syscall.SIGTERM {
+					cmd.Process.Signal(sig)
+				}
+			case err := <-done:
+				record.ExitCode = cmd.ProcessState.ExitCode()
+				if err != nil && record.ExitCode <= 0 {
+					record.ExitCode = 1
+				}
+				break wait
+			}
+		}
+	}
+
+	restoreMaintenanceTargets(e, targets)
+	record.Targets = targets
+}
+
+// receivedSignal returns the signal waiting in the channel, or nil when there is none.
+func receivedSignal(signals chan os.Signal) os.Signal {
+	select {
+	case sig := <-signals:
+		return sig
+	default:
+		return nil
+	}
+}
+
+// resolveMaintenanceTargets looks up the given integrations and policies by name to learn their current state.
+func resolveMaintenanceTargets(t *toggler, integrations []string, policies []string) ([]maintenanceTarget, error) {
+	var targets []maintenanceTarget
+	for _, name := range integrations {
+		found, err := findIntegration(t.restClient(), name)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, maintenanceTarget{Type: "integration", ID: found.ID, Name: found.Name, WasEnabled: found.Enabled})
+	}
+	for _, name := range policies {
+		found, err := findPolicy(t.restClient(), "", name)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, maintenanceTarget{Type: "policy", ID: found.ID, Name: found.Name, WasEnabled: found.Enabled})
+	}
+	return targets, nil
+}
+
+// muteMaintenanceTargets disables the enabled targets and reports whether all of them could be disabled.
+// Each one is first recorded like reEnable to be re-enabled by the reconcile command, so that it is
+// restored even if lamp itself gets killed.
+func muteMaintenanceTargets(e enabler, targets []maintenanceTarget, reEnable pendingEnable) bool {
+	muted := true
+	for i := range targets {
+		target := &targets[i]
+		if !target.WasEnabled {
+			fmt.Printf("%s %s is already disabled\n", capitalize(target.Type), target.Name)
+			continue
+		}
+		entry := reEnable
+		entry.Type, entry.ID, entry.Name = target.Type, target.ID, target.Name
+		if err := schedulePendingEnable(entry); err != nil {
+			printWarningMessage("WARNING: Could not record the re-enable of " + target.Name + ": " + err.Error())
+		}
+		if err := e.setEnabled(target.Type, target.ID, "", false); err != nil {
+			fmt.Printf("Could not disable %s %s: %s\n", target.Type, target.Name, err.Error())
+			target.Error = err.Error()
+			muted = false
+			if err := removePendingEnable(target.Type, target.ID, ""); err != nil {
+				printWarningMessage("WARNING: Could not remove the scheduled re-enable: " + err.Error())
+			}
+			continue
+		}
+		target.Disabled = true
+		fmt.Printf("%s %s disabled\n", capitalize(target.Type), target.Name)
+	}
+	return muted
+}
+
+// restoreMaintenanceTargets re-enables the targets disabled by muteMaintenanceTargets.
+// The ones that fail stay recorded for the reconcile command.
+func restoreMaintenanceTargets(e enabler, targets []maintenanceTarget) {
+	for i := range targets {
+		target := &targets[i]
+		if !target.Disabled {
+			continue
+		}
+		if err := e.setEnabled(target.Type, target.ID, "", true); err != nil {
+			fmt.Printf("Could not re-enable %s %s, 'lamp reconcile' will retry: %s\n", target.Type, target.Name, err.Error())
+			target.Error = err.Error()
+			continue
+		}
+		target.Restored = true
+		if err := removePendingEnable(target.Type, target.ID, ""); err != nil {
+			printWarningMessage("WARNING: Could not remove the scheduled re-enable: " + err.Error())
+		}
+		fmt.Printf("%s %s re-enabled\n", capitalize(target.Type), target.Name)
+	}
+}
+
+// writeMaintenanceRecord appends the record to the maintenance log under the state directory and to the lamp log.
+func writeMaintenanceRecord(record maintenanceRecord) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		printWarningMessage("WARNING: Could not record the maintenance run: " + err.Error())
+		return
+	}
+	if log.Logger() != nil {
+		log.Logger().Info("Maintenance run: " + string(line))
+	}
+
+	path := cfg.StatePath(maintenanceLogFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		printWarningMessage("WARNING: Could not record the maintenance run: " + err.Error())
+		return
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		printWarningMessage("WARNING: Could not record the maintenance run: " + err.Error())
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		printWarningMessage("WARNING: Could not record the maintenance run: " + err.Error())
+		return
+	}
+	printVerboseMessage("Maintenance run is recorded to: " + path)
+}
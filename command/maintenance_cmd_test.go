@@ -0,0 +1,160 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMaintenanceRecord(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	os.Setenv("LAMP_STATE_DIR", dir)
+	defer os.Unsetenv("LAMP_STATE_DIR")
+
+	started := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	records := []maintenanceRecord{
+		{
+			StartedAt:  started,
+			FinishedAt: started.Add(time.Minute),
+			User:       "deployer",
+			Command:    []string{"./deploy.sh", "--fast"},
+			Targets: []maintenanceTarget{
+				{Type: "integration", ID: "1", Name: "web", WasEnabled: true, Disabled: true, Restored: true},
+				{Type: "policy", ID: "2", Name: "mute"},
+			},
+		},
+		{
+			StartedAt:  started.Add(time.Hour),
+			FinishedAt: started.Add(2 * time.Hour),
+			Command:    []string{"false"},
+			ExitCode:   1,
+			Signal:     "interrupt",
+			Targets:    []maintenanceTarget{{Type: "integration", ID: "1", Name: "web", WasEnabled: true, Disabled: true, Error: "timeout"}},
+		},
+	}
+	for _, record := range records {
+		writeMaintenanceRecord(record)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, maintenanceLogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(lines) != len(records) {
+		t.Fatalf("maintenance log has %d lines, want %d:\n%s", len(lines), len(records), content)
+	}
+	for i, line := range lines {
+		var got maintenanceRecord
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line %d is not a record: %v", i+1, err)
+		}
+		if !reflect.DeepEqual(got, records[i]) {
+			t.Errorf("line %d = %+v, want %+v", i+1, got, records[i])
+		}
+	}
+}
+
+// fakeEnabler records the enable/disable calls, failing the ones for the ids in fail.
+type fakeEnabler struct {
+	calls []string
+	fail  map[string]bool
+}
+
+func (f *fakeEnabler) setEnabled(kind string, id string, name string, enabled bool) error {
+	f.calls = append(f.calls, fmt.Sprintf("%s %s enabled=%t", kind, id, enabled))
+	if f.fail[id] {
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
+func TestRunMaintenance(t *testing.T) {
+	tests := []struct {
+		name         string
+		signal       os.Signal
+		fail         map[string]bool
+		wantCalls    []string
+		wantRun      bool
+		wantExitCode int
+		wantSignal   string
+		wantTargets  []maintenanceTarget
+	}{
+		{
+			name:         "mute, run and restore",
+			wantCalls:    []string{"integration 1 enabled=false", "integration 1 enabled=true"},
+			wantRun:      true,
+			wantExitCode: 3,
+			wantTargets: []maintenanceTarget{
+				{Type: "integration", ID: "1", Name: "web", WasEnabled: true, Disabled: true, Restored: true},
+				{Type: "policy", ID: "2", Name: "mute"},
+			},
+		},
+		{
+			name:         "signal received while muting",
+			signal:       os.Interrupt,
+			wantCalls:    []string{"integration 1 enabled=false", "integration 1 enabled=true"},
+			wantExitCode: 1,
+			wantSignal:   "interrupt",
+			wantTargets: []maintenanceTarget{
+				{Type: "integration", ID: "1", Name: "web", WasEnabled: true, Disabled: true, Restored: true},
+				{Type: "policy", ID: "2", Name: "mute"},
+			},
+		},
+		{
+			name:         "muting fails",
+			fail:         map[string]bool{"1": true},
+			wantCalls:    []string{"integration 1 enabled=false"},
+			wantExitCode: 1,
+			wantTargets: []maintenanceTarget{
+				{Type: "integration", ID: "1", Name: "web", WasEnabled: true, Error: "forbidden"},
+				{Type: "policy", ID: "2", Name: "mute"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			os.Setenv("LAMP_STATE_DIR", dir)
+			defer os.Unsetenv("LAMP_STATE_DIR")
+
+			marker := filepath.Join(dir, "ran")
+			record := maintenanceRecord{Command: []string{"sh", "-c", "touch " + marker + "; exit 3"}}
+			targets := []maintenanceTarget{
+				{Type: "integration", ID: "1", Name: "web", WasEnabled: true},
+				{Type: "policy", ID: "2", Name: "mute"},
+			}
+			signals := make(chan os.Signal, 1)
+			if test.signal != nil {
+				signals <- test.signal
+			}
+			e := &fakeEnabler{fail: test.fail}
+			captureStdout(t, func() {
+				runMaintenance(e, &record, targets, pendingEnable{EnableAt: time.Now().Add(time.Hour)}, signals)
+			})
+
+			if !reflect.DeepEqual(e.calls, test.wantCalls) {
+				t.Errorf("calls = %q, want %q", e.calls, test.wantCalls)
+			}
+			if _, err := os.Stat(marker); (err == nil) != test.wantRun {
+				t.Errorf("command ran = %t, want %t", err == nil, test.wantRun)
+			}
+			if record.ExitCode != test.wantExitCode || record.Signal != test.wantSignal {
+				t.Errorf("exit code %d and signal %q, want %d and %q", record.ExitCode, record.Signal, test.wantExitCode, test.wantSignal)
+			}
+			if !reflect.DeepEqual(record.Targets, test.wantTargets) {
+				t.Errorf("targets = %+v, want %+v", record.Targets, test.wantTargets)
+			}
+			if entries, err := loadPendingEnables(); err != nil || len(entries) != 0 {
+				t.Errorf("scheduled re-enables left = %+v, %v", entries, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	gcli "github.com/codegangsta/cli"
+	"github.com/opsgenie/opsgenie-go-sdk/schedulev2"
+)
+
+// CreateOverrideAction creates a schedule override that puts the given user on call between --start and --end.
+// The override is not sent when it overlaps an existing override of the same rotations.
+func CreateOverrideAction(c *gcli.Context) {
+	schedule := grabOverrideSchedule(c)
+	username, success := getVal("username", c)
+	if !success {
+		fmt.Printf("Username of the user to put on call must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	if _, success := getVal("start", c); !success {
+		fmt.Printf("Start of the override must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	if _, success := getVal("end", c); !success {
+		fmt.Printf("End of the override must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	start := grabTimeArg(c, "start", time.Time{})
+	end := grabTimeArg(c, "end", time.Time{})
+	if !end.After(start) {
+		fmt.Printf("End of the override must be after its start\n")
+		os.Exit(1)
+	}
+	if !end.After(time.Now()) {
+		fmt.Printf("End of the override must be in the future\n")
+		os.Exit(1)
+	}
+
+	req := schedulev2.CreateOverrideRequest{
+		ScheduleID:   schedule.ID,
+		ScheduleName: schedule.Name,
+		User:         schedulev2.Participant{Type: "user", Username: username},
+		StartDate:    start,
+		EndDate:      end,
+	}
+	if val, success := getVal("alias", c); success {
+		req.Alias = val
+	}
+	for _, rotation := range c.StringSlice("rotation") {
+		isEmpty("rotation", rotation, c)
+		req.Rotations = append(req.Rotations, schedulev2.RotationIdentifier{Name: rotation})
+	}
+
+	cli, err := NewScheduleClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	printVerboseMessage("Checking the existing overrides of the schedule for overlaps..")
+
+	existing, err := cli.ListOverrides(schedulev2.ListOverridesRequest{ScheduleID: schedule.ID, ScheduleName: schedule.Name})
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	if overlapping := findOverlappingOverrides(existing.Overrides, req); len(overlapping) > 0 {
+		fmt.Printf("Override is not created, it overlaps existing overrides:\n")
+		for _, override := range overlapping {
+			fmt.Printf("  %s\n", describeOverride(override))
+		}
+		os.Exit(1)
+	}
+
+	printVerboseMessage("Create override request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.CreateOverride(req)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Override created successfully.")
+	fmt.Printf("alias=%s\n", resp.Alias)
+}
+
+// ListOverridesAction retrieves the overrides of the specified schedule from OpsGenie.
+func ListOverridesAction(c *gcli.Context) {
+	schedule := grabOverrideSchedule(c)
+
+	cli, err := NewScheduleClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	printVerboseMessage("List overrides request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.ListOverrides(schedulev2.ListOverridesRequest{ScheduleID: schedule.ID, ScheduleName: schedule.Name})
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Overrides listed successfully.")
+	printResult(c, overrideList(resp.Overrides))
+}
+
+// DeleteOverrideAction deletes the override with the given alias from the specified schedule.
+func DeleteOverrideAction(c *gcli.Context) {
+	schedule := grabOverrideSchedule(c)
+	alias, success := getVal("alias", c)
+	if !success {
+		fmt.Printf("Alias of the override must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+
+	cli, err := NewScheduleClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	printVerboseMessage("Delete override request prepared from flags, sending request to OpsGenie..")
+
+	_, err = cli.DeleteOverride(schedulev2.DeleteOverrideRequest{ScheduleID: schedule.ID, ScheduleName: schedule.Name, Alias: alias})
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Override %s deleted successfuly\n", alias)
+}
+
+// grabOverrideSchedule returns the schedule given by --schedule or --scheduleId, exiting when neither is provided.
+func grabOverrideSchedule(c *gcli.Context) schedulev2.GetScheduleRequest {
+	schedule := scheduleFromFlags(c)
+	if schedule.ID == "" && schedule.Name == "" {
+		fmt.Printf("Either schedule or scheduleId must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	return schedule
+}
+
+// findOverlappingOverrides returns the overrides whose period intersects the requested one and which apply to
+// at least one of the same rotations. An override without rotations applies to all rotations of the schedule.
+func findOverlappingOverrides(overrides []schedulev2.Override, req schedulev2.CreateOverrideRequest) []schedulev2.Override {
+	var overlapping []schedulev2.Override
+	for _, override := range overrides {
+		if req.Alias != "" && override.Alias == req.Alias {
+			continue
+		}
+		if !override.StartDate.Before(req.EndDate) || !req.StartDate.Before(override.EndDate) {
+			continue
+		}
+		if sharesRotation(override.Rotations, req.Rotations) {
+			overlapping = append(overlapping, override)
+		}
+	}
+	return overlapping
+}
+
+func sharesRotation(a []schedulev2.RotationIdentifier, b []schedulev2.RotationIdentifier) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if (x.ID != "" && x.ID == y.ID) || (x.Name != "" && strings.EqualFold(x.Name, y.Name)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func describeOverride(o schedulev2.Override) string {
+	return fmt.Sprintf("%s: %s from %s to %s", o.Alias, participantName(o.User),
+		formatUserTime(o.StartDate), formatUserTime(o.EndDate))
+}
+
+func rotationNames(rotations []schedulev2.RotationIdentifier) string {
+	if len(rotations) == 0 {
+		return "all"
+	}
+	var names []string
+	for _, r := range rotations {
+		if r.Name != "" {
+			names = append(names, r.Name)
+		} else {
+			names = append(names, r.ID)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// overrideList prints schedule overrides in table output format.
+type overrideList []schedulev2.Override
+
+func (l overrideList) tableHeaders() []string {
+	return []string{"alias", "user", "startDate", "endDate", "rotations"}
+}
+
+func (l overrideList) tableRows() [][]string {
+	var rows [][]string
+	for _, o := range l {
+		rows = append(rows, []string{o.Alias, participantName(o.User), formatUserTime(o.StartDate),
+			formatUserTime(o.EndDate), rotationNames(o.Rotations)})
+	}
+	return rows
+}
@@ -0,0 +1,80 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk/schedulev2"
+)
+
+func TestFindOverlappingOverrides(t *testing.T) {
+	base := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time {
+		return base.Add(time.Duration(hours) * time.Hour)
+	}
+	primary := schedulev2.RotationIdentifier{ID: "r1", Name: "Primary"}
+	secondary := schedulev2.RotationIdentifier{ID: "r2", Name: "Secondary"}
+	existing := []schedulev2.Override{
+		{Alias: "all-rotations", StartDate: at(0), EndDate: at(8)},
+		{Alias: "primary", StartDate: at(10), EndDate: at(12), Rotations: []schedulev2.RotationIdentifier{primary}},
+		{Alias: "secondary", StartDate: at(10), EndDate: at(12), Rotations: []schedulev2.RotationIdentifier{secondary}},
+	}
+
+	tests := []struct {
+		name string
+		req  schedulev2.CreateOverrideRequest
+		want []string
+	}{
+		{
+			name: "before every override",
+			req:  schedulev2.CreateOverrideRequest{StartDate: at(-4), EndDate: at(0)},
+			want: nil,
+		},
+		{
+			name: "touching the end is no overlap",
+			req:  schedulev2.CreateOverrideRequest{StartDate: at(8), EndDate: at(10)},
+			want: nil,
+		},
+		{
+			name: "overlaps an override of all rotations",
+			req:  schedulev2.CreateOverrideRequest{StartDate: at(7), EndDate: at(9), Rotations: []schedulev2.RotationIdentifier{secondary}},
+			want: []string{"all-rotations"},
+		},
+		{
+			name: "all rotations overlap every rotation",
+			req:  schedulev2.CreateOverrideRequest{StartDate: at(11), EndDate: at(13)},
+			want: []string{"primary", "secondary"},
+		},
+		{
+			name: "rotation matched by name",
+			req:  schedulev2.CreateOverrideRequest{StartDate: at(9), EndDate: at(11), Rotations: []schedulev2.RotationIdentifier{{Name: "primary"}}},
+			want: []string{"primary"},
+		},
+		{
+			name: "other rotation",
+			req:  schedulev2.CreateOverrideRequest{StartDate: at(9), EndDate: at(11), Rotations: []schedulev2.RotationIdentifier{{ID: "r3"}}},
+			want: nil,
+		},
+		{
+			name: "replacing the override with the same alias",
+			req:  schedulev2.CreateOverrideRequest{Alias: "primary", StartDate: at(10), EndDate: at(11), Rotations: []schedulev2.RotationIdentifier{primary}},
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, override := range findOverlappingOverrides(existing, test.req) {
+				got = append(got, override.Alias)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("findOverlappingOverrides() = %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("findOverlappingOverrides() = %v, want %v", got, test.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	gcli "github.com/codegangsta/cli"
+	hb "github.com/opsgenie/opsgenie-go-sdk/heartbeat"
+	"github.com/opsgenie/opsgenie-lamp/cfg"
+)
+
+// PingAction prints the API endpoint requests are sent to, and checks that the API key is accepted there
+// by listing the heartbeats. It exits with 1 when the request fails.
+func PingAction(c *gcli.Context) {
+	cli, err := NewHeartbeatClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+	region, _ := getVal("region", c)
+	endpoint, err := cfg.ResolveEndpoint(region)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	result := pingResult{Endpoint: endpoint, Profile: cfg.ActiveProfile(), Status: "ok"}
+
+	printVerboseMessage("Ping request prepared, sending request to " + endpoint.URL + "..")
+	if _, err := cli.List(hb.ListHeartbeatsRequest{}); err != nil {
+		result.Status = "failed: " + err.Error()
+		printResult(c, result)
+		os.Exit(1)
+	}
+	printResult(c, result)
+}
+
+// pingResult prints the resolved endpoint and the result of the request in table output format.
+type pingResult struct {
+	cfg.Endpoint
+	Profile string `json:"profile"`
+	Status  string `json:"status"`
+}
+
+func (r pingResult) tableHeaders() []string {
+	return []string{"url", "region", "source", "profile", "status"}
+}
+
+func (r pingResult) tableRows() [][]string {
+	return [][]string{{r.URL, r.Region, r.Source, r.Profile, r.Status}}
+}
@@ -0,0 +1,138 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	gcli "github.com/codegangsta/cli"
+	ogcli "github.com/opsgenie/opsgenie-go-sdk/client"
+	"github.com/opsgenie/opsgenie-lamp/cfg"
+)
+
+const (
+	defaultConnectionTimeout = 30 * time.Second
+	defaultRequestTimeout    = 60 * time.Second
+)
+
+// restClient calls the OpsGenie REST API endpoints that the SDK has no client methods for,
+// such as listing, getting, creating and updating integrations and policies.
+type restClient struct {
+	apiKey  string
+	baseURL string
+	http    *http.Client
+}
+
+// newRESTClient instantiates a restClient with the API key, endpoint, proxy and timeouts the SDK clients are configured with.
+func newRESTClient(c *gcli.Context) *restClient {
+	if c.IsSet("v") {
+		verbose = true
+		printVerboseMessage("Will execute command in verbose mode.")
+	}
+	readConfigFile(c)
+
+	timeouts := connectionConf()
+	if timeouts.ConnectionTimeout == 0 {
+		timeouts.ConnectionTimeout = defaultConnectionTimeout
+	}
+	if timeouts.RequestTimeout == 0 {
+		timeouts.RequestTimeout = defaultRequestTimeout
+	}
+	transport := &http.Transport{
+		Proxy:       http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{Timeout: timeouts.ConnectionTimeout}).DialContext,
+	}
+	proxyHost := cfg.Get("proxyHost")
+	proxyPort, err := strconv.Atoi(cfg.Get("proxyPort"))
+	if err == nil && proxyPort != 0 && proxyHost != "" {
+		transport.Proxy = http.ProxyURL(proxyURL(proxyConf(proxyHost, proxyPort)))
+	}
+
+	printVerboseMessage("REST Client created..")
+	return &restClient{
+		apiKey:  grabAPIKey(c),
+		baseURL: strings.TrimSuffix(resolveEndpoint(c).URL, "/"),
+		http:    &http.Client{Transport: transport, Timeout: timeouts.RequestTimeout},
+	}
+}
+
+// proxyURL converts the proxy settings of the SDK clients into a proxy URL.
+func proxyURL(pc *ogcli.ProxyConfiguration) *url.URL {
+	protocol := pc.Protocol
+	if protocol == "" {
+		protocol = "http"
+	}
+	u := &url.URL{Scheme: protocol, Host: net.JoinHostPort(pc.Host, strconv.Itoa(pc.Port))}
+	if pc.Username != "" {
+		u.User = url.UserPassword(pc.Username, pc.Password)
+	}
+	return u
+}
+
+/*
+The 'do' method sends the request to the given path of the API, with the body
+marshalled into JSON. The "data" field of the response is decoded into out,
+unless out is nil. Responses with a status other than 2xx are returned as
+errors with the message given by OpsGenie.
+*/
+func (r *restClient) do(method string, path string, query url.Values, body interface{}, out interface{}) error {
+	endpoint := r.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	var content []byte
+	if body != nil {
+		var err error
+		if content, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, endpoint, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "GenieKey "+r.apiKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	printVerboseMessage("Sending " + method + " request to " + endpoint)
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	content, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var failure struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(content, &failure) == nil && failure.Message != "" {
+			return fmt.Errorf("%s (status %d)", failure.Message, resp.StatusCode)
+		}
+		return errors.New("OpsGenie returned " + resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	envelope := struct {
+		Data interface{} `json:"data"`
+	}{Data: out}
+	if err := json.Unmarshal(content, &envelope); err != nil {
+		return errors.New("Could not parse the response of OpsGenie. " + err.Error())
+	}
+	return nil
+}
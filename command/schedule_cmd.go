@@ -0,0 +1,421 @@
+package command
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gcli "github.com/codegangsta/cli"
+	ogcli "github.com/opsgenie/opsgenie-go-sdk/client"
+	"github.com/opsgenie/opsgenie-go-sdk/schedulev2"
+)
+
+// OnCallAction prints the current and next on-call participants of a schedule, or of every schedule owned by a team.
+func OnCallAction(c *gcli.Context) {
+	at := grabTimeArg(c, "at", time.Now())
+	flat := c.IsSet("flat")
+
+	cli, err := NewScheduleClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	schedules := grabOnCallSchedules(c, cli)
+
+	printVerboseMessage("On-call requests prepared from flags, sending requests to OpsGenie..")
+
+	var result onCallList
+	for _, schedule := range schedules {
+		entry, err := getOnCall(cli, schedule, at, flat)
+		if err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
+		}
+		result = append(result, entry)
+	}
+	printVerboseMessage("Got on-call participants successfully.")
+	printResult(c, result)
+}
+
+// ListSchedulesAction retrieves the schedules defined at OpsGenie.
+func ListSchedulesAction(c *gcli.Context) {
+	cli, err := NewScheduleClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	printVerboseMessage("List schedules request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.List(schedulev2.ListSchedulesRequest{})
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Schedules listed successfully.")
+	printResult(c, scheduleList(resp.Schedules))
+}
+
+// GetScheduleAction retrieves the specified schedule with its rotations from OpsGenie.
+func GetScheduleAction(c *gcli.Context) {
+	cli, err := NewScheduleClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	printVerboseMessage("Get schedule request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.Get(grabScheduleIdentifier(c))
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Got schedule successfully.")
+	printResult(c, scheduleRotationTable(resp.Schedule))
+}
+
+// ScheduleTimelineAction prints the on-call shifts of the specified schedule between --from and --to.
+func ScheduleTimelineAction(c *gcli.Context) {
+	from, to := grabTimeRange(c)
+
+	cli, err := NewScheduleClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	printVerboseMessage("Schedule timeline request prepared from flags, sending request to OpsGenie..")
+
+	shifts, err := getScheduleShifts(cli, grabScheduleIdentifier(c), from, to)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Got schedule timeline successfully.")
+	printResult(c, shifts)
+}
+
+// ExportScheduleAction writes the on-call shifts of the specified schedule between --from and --to to an
+// iCalendar file, one event per shift.
+func ExportScheduleAction(c *gcli.Context) {
+	format := "ics"
+	if val, success := getVal("format", c); success {
+		format = strings.ToLower(val)
+	}
+	if format != "ics" {
+		fmt.Printf("Unsupported export format %s, only ics is supported\n", format)
+		os.Exit(1)
+	}
+	identifier := grabScheduleIdentifier(c)
+	from, to := grabTimeRange(c)
+
+	cli, err := NewScheduleClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	resp, err := cli.Get(identifier)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	schedule := resp.Schedule
+
+	printVerboseMessage("Schedule timeline request prepared from flags, sending request to OpsGenie..")
+
+	shifts, err := getScheduleShifts(cli, schedulev2.GetScheduleRequest{ID: schedule.ID, Name: schedule.Name}, from, to)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	path, success := getVal("output", c)
+	if !success {
+		path = unsafeFileNameChars.ReplaceAllString(schedule.Name, "_") + ".ics"
+	}
+	out := os.Stdout
+	if path != "-" {
+		if out, err = os.Create(path); err != nil {
+			fmt.Printf("Cannot create file %s. %s\n", path, err.Error())
+			os.Exit(1)
+		}
+	}
+	if err := writeICS(out, schedule, shifts, time.Now()); err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	if path != "-" {
+		if err := out.Close(); err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
+		}
+		fmt.Printf("%d shifts of schedule %s written to %s\n", len(shifts), schedule.Name, path)
+	}
+}
+
+// onCall holds the current and next on-call participants of a schedule.
+// Participants are only filled when the result is not flat, recipients only when it is.
+type onCall struct {
+	Schedule               string                   `json:"schedule"`
+	Date                   time.Time                `json:"date"`
+	OnCallParticipants     []schedulev2.Participant `json:"onCallParticipants,omitempty"`
+	OnCallRecipients       []string                 `json:"onCallRecipients,omitempty"`
+	NextOnCallParticipants []schedulev2.Participant `json:"nextOnCallParticipants,omitempty"`
+	NextOnCallRecipients   []string                 `json:"nextOnCallRecipients,omitempty"`
+}
+
+// getOnCall retrieves the on-call participants of the schedule at the given time and the ones that follow them.
+func getOnCall(cli *ogcli.OpsGenieScheduleV2Client, schedule schedulev2.GetScheduleRequest, at time.Time, flat bool) (onCall, error) {
+	entry := onCall{Schedule: schedule.Name, Date: at}
+	if entry.Schedule == "" {
+		entry.Schedule = schedule.ID
+	}
+
+	current, err := cli.GetOnCalls(schedulev2.GetOnCallsRequest{ID: schedule.ID, Name: schedule.Name, Flat: flat, Date: &at})
+	if err != nil {
+		return entry, err
+	}
+	next, err := cli.GetNextOnCalls(schedulev2.GetNextOnCallsRequest{ID: schedule.ID, Name: schedule.Name, Flat: flat, Date: &at})
+	if err != nil {
+		return entry, err
+	}
+	if flat {
+		entry.OnCallRecipients = current.OnCallRecipients
+		entry.NextOnCallRecipients = next.NextOnCallRecipientsFlat
+	} else {
+		entry.OnCallParticipants = current.OnCallParticipants
+		entry.NextOnCallParticipants = next.NextOnCallRecipients
+	}
+	return entry, nil
+}
+
+// grabOnCallSchedules returns the schedule given by --schedule or --scheduleId, or the schedules owned by the --team.
+func grabOnCallSchedules(c *gcli.Context, cli *ogcli.OpsGenieScheduleV2Client) []schedulev2.GetScheduleRequest {
+	teamName, byTeam := getVal("team", c)
+	if !byTeam {
+		req := scheduleFromFlags(c)
+		if req.ID == "" && req.Name == "" {
+			fmt.Printf("Either schedule, scheduleId or team must be provided\n")
+			gcli.ShowCommandHelp(c, c.Command.Name)
+			os.Exit(1)
+		}
+		return []schedulev2.GetScheduleRequest{req}
+	}
+
+	printVerboseMessage("Looking up the schedules of team " + teamName + "..")
+
+	resp, err := cli.List(schedulev2.ListSchedulesRequest{})
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	var schedules []schedulev2.GetScheduleRequest
+	for _, schedule := range resp.Schedules {
+		if strings.EqualFold(schedule.OwnerTeam.Name, teamName) || schedule.OwnerTeam.ID == teamName {
+			schedules = append(schedules, schedulev2.GetScheduleRequest{ID: schedule.ID, Name: schedule.Name})
+		}
+	}
+	if len(schedules) == 0 {
+		fmt.Printf("Team %s does not own any schedule\n", teamName)
+		os.Exit(1)
+	}
+	return schedules
+}
+
+// scheduleFromFlags returns the schedule given by --schedule or --scheduleId. Both are empty when neither is provided.
+func scheduleFromFlags(c *gcli.Context) schedulev2.GetScheduleRequest {
+	req := schedulev2.GetScheduleRequest{}
+	if val, success := getVal("scheduleId", c); success {
+		req.ID = val
+	}
+	if val, success := getVal("schedule", c); success {
+		req.Name = val
+	}
+	return req
+}
+
+// grabScheduleIdentifier returns the schedule given by --id or --name, exiting when neither is provided.
+func grabScheduleIdentifier(c *gcli.Context) schedulev2.GetScheduleRequest {
+	req := schedulev2.GetScheduleRequest{}
+	if val, success := getVal("id", c); success {
+		req.ID = val
+	}
+	if val, success := getVal("name", c); success {
+		req.Name = val
+	}
+	if req.ID == "" && req.Name == "" {
+		fmt.Printf("Either id or name of the schedule must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	return req
+}
+
+// grabTimeArg parses the time given by the named flag, returning def when it is not provided.
+func grabTimeArg(c *gcli.Context, name string, def time.Time) time.Time {
+	val, success := getVal(name, c)
+	if !success {
+		return def
+	}
+	t, err := parseTimeArg(val, time.Now())
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	return t
+}
+
+// grabTimeRange returns the range given by --from and --to. It starts now and spans a week by default.
+func grabTimeRange(c *gcli.Context) (time.Time, time.Time) {
+	from := grabTimeArg(c, "from", time.Now())
+	to := grabTimeArg(c, "to", from.AddDate(0, 0, 7))
+	if !to.After(from) {
+		fmt.Printf("The end of the range must be after its start\n")
+		os.Exit(1)
+	}
+	return from, to
+}
+
+// shift is a period of a rotation during which a participant is on call.
+type shift struct {
+	Rotation    string    `json:"rotation"`
+	RotationID  string    `json:"rotationId,omitempty"`
+	Participant string    `json:"participant"`
+	Type        string    `json:"type,omitempty"`
+	StartDate   time.Time `json:"startDate"`
+	EndDate     time.Time `json:"endDate"`
+	// periodStart is the start of the timeline period before it was clipped to the requested range.
+	periodStart time.Time
+}
+
+// getScheduleShifts retrieves the final timeline of the schedule and returns its shifts clipped to the given range,
+// ordered by their start.
+func getScheduleShifts(cli *ogcli.OpsGenieScheduleV2Client, schedule schedulev2.GetScheduleRequest, from time.Time, to time.Time) (shiftList, error) {
+	days := int(math.Ceil(to.Sub(from).Hours() / 24))
+	resp, err := cli.GetTimeline(schedulev2.GetTimelineRequest{
+		ID:           schedule.ID,
+		Name:         schedule.Name,
+		IntervalUnit: "days",
+		Interval:     days,
+		Date:         &from,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var shifts shiftList
+	for _, rotation := range resp.FinalTimeline.Rotations {
+		for _, period := range rotation.Periods {
+			if !period.EndDate.After(from) || !period.StartDate.Before(to) {
+				continue
+			}
+			s := shift{
+				Rotation:    rotation.Name,
+				RotationID:  rotation.ID,
+				Participant: participantName(period.Recipient),
+				Type:        period.Type,
+				StartDate:   period.StartDate,
+				EndDate:     period.EndDate,
+				periodStart: period.StartDate,
+			}
+			if s.StartDate.Before(from) {
+				s.StartDate = from
+			}
+			if s.EndDate.After(to) {
+				s.EndDate = to
+			}
+			shifts = append(shifts, s)
+		}
+	}
+	sort.SliceStable(shifts, func(i, j int) bool {
+		return shifts[i].StartDate.Before(shifts[j].StartDate)
+	})
+	return shifts, nil
+}
+
+func participantName(p schedulev2.Participant) string {
+	if p.Username != "" {
+		return p.Username
+	}
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.ID
+}
+
+func participantNames(participants []schedulev2.Participant) string {
+	var names []string
+	for _, p := range participants {
+		names = append(names, participantName(p))
+	}
+	return strings.Join(names, ", ")
+}
+
+// onCallList prints on-call participants in table output format.
+type onCallList []onCall
+
+func (l onCallList) tableHeaders() []string {
+	return []string{"schedule", "onCall", "next"}
+}
+
+func (l onCallList) tableRows() [][]string {
+	var rows [][]string
+	for _, entry := range l {
+		current := strings.Join(entry.OnCallRecipients, ", ")
+		next := strings.Join(entry.NextOnCallRecipients, ", ")
+		if len(entry.OnCallParticipants) > 0 || len(entry.NextOnCallParticipants) > 0 {
+			current = participantNames(entry.OnCallParticipants)
+			next = participantNames(entry.NextOnCallParticipants)
+		}
+		rows = append(rows, []string{entry.Schedule, current, next})
+	}
+	return rows
+}
+
+// scheduleList prints schedules in table output format.
+type scheduleList []schedulev2.Schedule
+
+func (l scheduleList) tableHeaders() []string {
+	return []string{"id", "name", "timezone", "enabled", "ownerTeam"}
+}
+
+func (l scheduleList) tableRows() [][]string {
+	var rows [][]string
+	for _, s := range l {
+		rows = append(rows, []string{s.ID, s.Name, s.Timezone, formatLeaf(s.Enabled), s.OwnerTeam.Name})
+	}
+	return rows
+}
+
+// scheduleRotationTable prints the rotations of a schedule in table output format.
+type scheduleRotationTable schedulev2.Schedule
+
+func (s scheduleRotationTable) tableHeaders() []string {
+	return []string{"rotation", "type", "length", "startDate", "endDate", "participants"}
+}
+
+func (s scheduleRotationTable) tableRows() [][]string {
+	var rows [][]string
+	for _, r := range s.Rotations {
+		rows = append(rows, []string{r.Name, r.Type, strconv.Itoa(r.Length), r.StartDate, r.EndDate,
+			participantNames(r.Participants)})
+	}
+	return rows
+}
+
+// shiftList prints schedule shifts in table output format.
+type shiftList []shift
+
+func (l shiftList) tableHeaders() []string {
+	return []string{"rotation", "participant", "startDate", "endDate"}
+}
+
+func (l shiftList) tableRows() [][]string {
+	var rows [][]string
+	for _, s := range l {
+		rows = append(rows, []string{s.Rotation, s.Participant, formatUserTime(s.StartDate), formatUserTime(s.EndDate)})
+	}
+	return rows
+}
@@ -0,0 +1,149 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	gcli "github.com/codegangsta/cli"
+	"github.com/opsgenie/opsgenie-lamp/cfg"
+)
+
+const pendingEnablesFile = "pending-enables.json"
+
+// pendingEnable is an integration/policy disabled for a limited time, waiting to be re-enabled by the reconcile command.
+// It is keyed by the ID, the name is kept to describe it. The profile and the endpoint it was disabled with are kept
+// too, since the state file is shared by all profiles.
+type pendingEnable struct {
+	Type       string    `json:"type"`
+	ID         string    `json:"id,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	Profile    string    `json:"profile,omitempty"`
+	Endpoint   string    `json:"endpoint,omitempty"`
+	DisabledAt time.Time `json:"disabledAt"`
+	EnableAt   time.Time `json:"enableAt"`
+}
+
+// newPendingEnable returns a re-enable after enableAt, recorded with the selected profile and the endpoint
+// the requests are sent to.
+func newPendingEnable(c *gcli.Context, enableAt time.Time) pendingEnable {
+	return pendingEnable{Profile: cfg.ActiveProfile(), Endpoint: resolveEndpoint(c).URL, DisabledAt: time.Now(), EnableAt: enableAt}
+}
+
+func (p pendingEnable) describe() string {
+	if p.Name != "" {
+		return p.Type + " " + p.Name
+	}
+	return p.Type + " " + p.ID
+}
+
+// ofActiveProfile reports whether the entry was recorded with the selected profile. Entries recorded
+// before the profile was kept belong to every profile.
+func (p pendingEnable) ofActiveProfile() bool {
+	return p.Profile == "" || p.Profile == cfg.ActiveProfile()
+}
+
+// sameTarget reports whether the entry is for the given integration/policy of the selected profile. Names are
+// only compared when the ID of either side is not known.
+func (p pendingEnable) sameTarget(kind string, id string, name string) bool {
+	if p.Type != kind || !p.ofActiveProfile() {
+		return false
+	}
+	if p.ID != "" && id != "" {
+		return p.ID == id
+	}
+	return p.Name != "" && p.Name == name
+}
+
+// loadPendingEnables reads the recorded re-enables from the state file, returning none if the file does not exist yet.
+func loadPendingEnables() ([]pendingEnable, error) {
+	path := cfg.StatePath(pendingEnablesFile)
+	printVerboseMessage("Reading scheduled re-enables from: " + path)
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("Could not read state file " + path + ". " + err.Error())
+	}
+	var entries []pendingEnable
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, errors.New("Could not parse state file " + path + ". " + err.Error())
+	}
+	return entries, nil
+}
+
+// savePendingEnables replaces the state file with the given re-enables.
+func savePendingEnables(entries []pendingEnable) error {
+	path := cfg.StatePath(pendingEnablesFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.New("Could not create state directory. " + err.Error())
+	}
+	if entries == nil {
+		entries = []pendingEnable{}
+	}
+	content, err := json.MarshalIndent(entries, "", "    ")
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so that an interrupted write can not lose the recorded re-enables.
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, content, 0600); err != nil {
+		return errors.New("Could not write state file " + tmpPath + ". " + err.Error())
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return errors.New("Could not write state file " + path + ". " + err.Error())
+	}
+	printVerboseMessage("Scheduled re-enables are saved to: " + path)
+	return nil
+}
+
+// schedulePendingEnable records the given re-enable, replacing any earlier one for the same integration/policy.
+func schedulePendingEnable(entry pendingEnable) error {
+	entries, err := loadPendingEnables()
+	if err != nil {
+		return err
+	}
+	var updated []pendingEnable
+	for _, e := range entries {
+		if !e.sameTarget(entry.Type, entry.ID, entry.Name) {
+			updated = append(updated, e)
+		}
+	}
+	return savePendingEnables(append(updated, entry))
+}
+
+// hasPendingEnableNamed reports whether a re-enable is recorded for the integration/policy with the given name.
+func hasPendingEnableNamed(kind string, name string) bool {
+	entries, err := loadPendingEnables()
+	if err != nil || name == "" {
+		return false
+	}
+	for _, e := range entries {
+		if e.Type == kind && e.Name == name && e.ofActiveProfile() {
+			return true
+		}
+	}
+	return false
+}
+
+// removePendingEnable drops the recorded re-enable of the given integration/policy, if any.
+func removePendingEnable(kind string, id string, name string) error {
+	entries, err := loadPendingEnables()
+	if err != nil {
+		return err
+	}
+	var updated []pendingEnable
+	for _, e := range entries {
+		if !e.sameTarget(kind, id, name) {
+			updated = append(updated, e)
+		}
+	}
+	if len(updated) == len(entries) {
+		return nil
+	}
+	return savePendingEnables(updated)
+}
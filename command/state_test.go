@@ -0,0 +1,133 @@
+package command
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-lamp/cfg"
+)
+
+// selectTestProfile loads a configuration file with the staging and prod profiles and selects the given one,
+// selecting the default profile again when the test ends.
+func selectTestProfile(t *testing.T, profile string) {
+	cfg.LoadConfigFromGivenPath(writeTestFile(t, "lamp.conf", "apiKey=key\n[staging]\napiKey=staging-key\n[prod]\napiKey=prod-key\n"))
+	if err := cfg.SelectProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cfg.SelectProfile("") })
+}
+
+func TestPendingEnableSameTarget(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry pendingEnable
+		kind  string
+		id    string
+		tName string
+		want  bool
+	}{
+		{name: "same id", entry: pendingEnable{Type: "integration", ID: "1", Name: "web"}, kind: "integration", id: "1", want: true},
+		{name: "same id other name", entry: pendingEnable{Type: "integration", ID: "1", Name: "web"}, kind: "integration", id: "1", tName: "renamed", want: true},
+		{name: "other id same name", entry: pendingEnable{Type: "integration", ID: "1", Name: "web"}, kind: "integration", id: "2", tName: "web", want: false},
+		{name: "other type", entry: pendingEnable{Type: "policy", ID: "1"}, kind: "integration", id: "1", want: false},
+		{name: "entry without id", entry: pendingEnable{Type: "policy", Name: "mute"}, kind: "policy", id: "3", tName: "mute", want: true},
+		{name: "target without id", entry: pendingEnable{Type: "policy", ID: "3", Name: "mute"}, kind: "policy", tName: "mute", want: true},
+		{name: "nothing to compare", entry: pendingEnable{Type: "policy", ID: "3"}, kind: "policy", tName: "mute", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.entry.sameTarget(test.kind, test.id, test.tName); got != test.want {
+				t.Errorf("sameTarget(%q, %q, %q) = %t, want %t", test.kind, test.id, test.tName, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPendingEnableOfActiveProfile(t *testing.T) {
+	selectTestProfile(t, "staging")
+
+	entry := pendingEnable{Type: "integration", ID: "1", Name: "web", Profile: "prod"}
+	if entry.sameTarget("integration", "1", "web") {
+		t.Error("sameTarget() = true for the entry of another profile")
+	}
+	entry.Profile = "staging"
+	if !entry.sameTarget("integration", "1", "web") {
+		t.Error("sameTarget() = false for the entry of the selected profile")
+	}
+	entry.Profile = ""
+	if !entry.sameTarget("integration", "1", "web") {
+		t.Error("sameTarget() = false for an entry recorded without a profile")
+	}
+}
+
+func TestSchedulePendingEnableByID(t *testing.T) {
+	os.Setenv("LAMP_STATE_DIR", t.TempDir())
+	defer os.Unsetenv("LAMP_STATE_DIR")
+
+	enableAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	// disabled by name, then by id: the second replaces the first
+	if err := schedulePendingEnable(pendingEnable{Type: "integration", ID: "1", Name: "web", EnableAt: enableAt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := schedulePendingEnable(pendingEnable{Type: "integration", ID: "1", EnableAt: enableAt.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := schedulePendingEnable(pendingEnable{Type: "policy", ID: "1", Name: "mute", EnableAt: enableAt}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := loadPendingEnables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []pendingEnable{
+		{Type: "integration", ID: "1", EnableAt: enableAt.Add(time.Hour)},
+		{Type: "policy", ID: "1", Name: "mute", EnableAt: enableAt},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Fatalf("entries = %+v, want %+v", entries, want)
+	}
+
+	// enabled by the name resolved to the same id
+	if err := removePendingEnable("integration", "1", "web"); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err = loadPendingEnables(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(entries, want[1:]) {
+		t.Errorf("entries = %+v, want %+v", entries, want[1:])
+	}
+}
+
+func TestHasPendingEnableNamed(t *testing.T) {
+	os.Setenv("LAMP_STATE_DIR", t.TempDir())
+	defer os.Unsetenv("LAMP_STATE_DIR")
+
+	if hasPendingEnableNamed("integration", "web") {
+		t.Error("hasPendingEnableNamed() = true without a state file")
+	}
+	entries := []pendingEnable{
+		{Type: "integration", ID: "1", Name: "web"},
+		{Type: "policy", ID: "2"},
+		{Type: "integration", ID: "3", Name: "api", Profile: "prod"},
+	}
+	if err := savePendingEnables(entries); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		kind, name string
+		want       bool
+	}{
+		{"integration", "web", true},
+		{"policy", "web", false},
+		{"integration", "api", false}, // recorded with the prod profile
+		{"policy", "", false},
+	}
+	for _, test := range tests {
+		if got := hasPendingEnableNamed(test.kind, test.name); got != test.want {
+			t.Errorf("hasPendingEnableNamed(%q, %q) = %t, want %t", test.kind, test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	gcli "github.com/codegangsta/cli"
+	"github.com/opsgenie/opsgenie-go-sdk/team"
+)
+
+// ListTeamsAction retrieves the teams defined at OpsGenie.
+func ListTeamsAction(c *gcli.Context) {
+	cli, err := NewTeamClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	printVerboseMessage("List teams request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.List(team.ListTeamsRequest{})
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Teams listed successfully.")
+	printResult(c, teamList(resp.Teams))
+}
+
+// GetTeamAction retrieves the specified team with its members from OpsGenie.
+func GetTeamAction(c *gcli.Context) {
+	cli, err := NewTeamClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	printVerboseMessage("Get team request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.Get(grabTeamIdentifier(c))
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Got team successfully.")
+	printResult(c, teamMemberTable(resp.Team))
+}
+
+// CreateTeamAction creates a team at OpsGenie.
+func CreateTeamAction(c *gcli.Context) {
+	cli, err := NewTeamClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	req := team.CreateTeamRequest{}
+	if val, success := getVal("name", c); success {
+		req.Name = val
+	} else {
+		fmt.Printf("Name of the team must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	if val, success := getVal("description", c); success {
+		req.Description = val
+	}
+	if val, success := getVal("members", c); success {
+		req.Members = parseTeamMembers(val)
+	}
+
+	printVerboseMessage("Create team request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.Create(req)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Team created successfully.")
+	fmt.Printf("id=%s\n", resp.ID)
+}
+
+// UpdateTeamAction updates the specified team at OpsGenie. The members are replaced only if --members is given.
+func UpdateTeamAction(c *gcli.Context) {
+	cli, err := NewTeamClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	current, err := cli.Get(grabTeamIdentifier(c))
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	req := team.UpdateTeamRequest{
+		ID:          current.ID,
+		Name:        current.Name,
+		Description: current.Description,
+		Members:     current.Members,
+	}
+	if val, success := getVal("rename", c); success {
+		req.Name = val
+	}
+	if val, success := getVal("description", c); success {
+		req.Description = val
+	}
+	if val, success := getVal("members", c); success {
+		req.Members = parseTeamMembers(val)
+	}
+
+	printVerboseMessage("Update team request prepared from flags, sending request to OpsGenie..")
+
+	if _, err := cli.Update(req); err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Team %s updated successfuly\n", req.Name)
+}
+
+// DeleteTeamAction deletes the specified team at OpsGenie.
+func DeleteTeamAction(c *gcli.Context) {
+	cli, err := NewTeamClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	identifier := grabTeamIdentifier(c)
+	req := team.DeleteTeamRequest{ID: identifier.ID, Name: identifier.Name}
+
+	printVerboseMessage("Delete team request prepared from flags, sending request to OpsGenie..")
+
+	if _, err := cli.Delete(req); err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Team deleted successfuly\n")
+}
+
+// AddTeamMembersAction adds the users given with --username to the team, or changes their role if they are already members.
+func AddTeamMembersAction(c *gcli.Context) {
+	role := "user"
+	if val, success := getVal("role", c); success {
+		role = val
+	}
+	changeTeamMembers(c, func(members []team.Member, username string) ([]team.Member, error) {
+		return addTeamMember(members, username, role), nil
+	})
+}
+
+// RemoveTeamMembersAction removes the users given with --username from the team.
+func RemoveTeamMembersAction(c *gcli.Context) {
+	changeTeamMembers(c, removeTeamMember)
+}
+
+// changeTeamMembers applies the change to the member list of the team for every --username and saves the team.
+func changeTeamMembers(c *gcli.Context, change func(members []team.Member, username string) ([]team.Member, error)) {
+	usernames := c.StringSlice("username")
+	if len(usernames) == 0 {
+		fmt.Printf("At least one user must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	cli, err := NewTeamClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	current, err := cli.Get(grabTeamIdentifier(c))
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	members := append([]team.Member{}, current.Members...)
+	for _, username := range usernames {
+		isEmpty("username", username, c)
+		if members, err = change(members, username); err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+	req := team.UpdateTeamRequest{
+		ID:          current.ID,
+		Name:        current.Name,
+		Description: current.Description,
+		Members:     members,
+	}
+
+	printVerboseMessage("Update team members request prepared from flags, sending request to OpsGenie..")
+
+	if _, err := cli.Update(req); err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Team %s has %d members now\n", current.Name, len(members))
+}
+
+// addTeamMember adds the user to the members with the role, or changes the role of the user if it is already a member.
+func addTeamMember(members []team.Member, username string, role string) []team.Member {
+	for i, member := range members {
+		if strings.EqualFold(member.User, username) {
+			members[i].Role = role
+			return members
+		}
+	}
+	return append(members, team.Member{User: username, Role: role})
+}
+
+// removeTeamMember removes the user from the members. The remaining members are never nil,
+// so that removing the last member sends an empty member list instead of leaving the members unchanged.
+func removeTeamMember(members []team.Member, username string) ([]team.Member, error) {
+	remaining := []team.Member{}
+	for _, member := range members {
+		if !strings.EqualFold(member.User, username) {
+			remaining = append(remaining, member)
+		}
+	}
+	if len(remaining) == len(members) {
+		return members, errors.New("User " + username + " is not a member of the team")
+	}
+	return remaining, nil
+}
+
+// grabTeamIdentifier returns the team given by --id or --name, exiting when neither is provided.
+func grabTeamIdentifier(c *gcli.Context) team.GetTeamRequest {
+	req := team.GetTeamRequest{}
+	if val, success := getVal("id", c); success {
+		req.ID = val
+	}
+	if val, success := getVal("name", c); success {
+		req.Name = val
+	}
+	if req.ID == "" && req.Name == "" {
+		fmt.Printf("Either id or name of the team must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	return req
+}
+
+// parseTeamMembers parses a comma separated list of members in the form username[:role]. The role defaults to user.
+func parseTeamMembers(val string) []team.Member {
+	var members []team.Member
+	for _, entry := range strings.Split(val, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		member := team.Member{User: parts[0], Role: "user"}
+		if len(parts) == 2 && parts[1] != "" {
+			member.Role = parts[1]
+		}
+		members = append(members, member)
+	}
+	return members
+}
+
+// teamList prints teams with their member counts in table output format.
+type teamList []team.Team
+
+func (l teamList) tableHeaders() []string {
+	return []string{"id", "name", "members", "description"}
+}
+
+func (l teamList) tableRows() [][]string {
+	var rows [][]string
+	for _, t := range l {
+		rows = append(rows, []string{t.ID, t.Name, strconv.Itoa(len(t.Members)), t.Description})
+	}
+	return rows
+}
+
+// teamMemberTable prints the members of a team in table output format.
+type teamMemberTable team.Team
+
+func (t teamMemberTable) tableHeaders() []string {
+	return []string{"user", "role"}
+}
+
+func (t teamMemberTable) tableRows() [][]string {
+	var rows [][]string
+	for _, member := range t.Members {
+		rows = append(rows, []string{member.User, member.Role})
+	}
+	return rows
+}
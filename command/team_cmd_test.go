@@ -0,0 +1,128 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opsgenie/opsgenie-go-sdk/team"
+)
+
+func TestParseTeamMembers(t *testing.T) {
+	tests := []struct {
+		val  string
+		want []team.Member
+	}{
+		{val: "jane@example.com", want: []team.Member{{User: "jane@example.com", Role: "user"}}},
+		{val: "jane@example.com:admin", want: []team.Member{{User: "jane@example.com", Role: "admin"}}},
+		{val: "jane@example.com:", want: []team.Member{{User: "jane@example.com", Role: "user"}}},
+		{
+			val: "jane@example.com:admin, joe@example.com",
+			want: []team.Member{
+				{User: "jane@example.com", Role: "admin"},
+				{User: "joe@example.com", Role: "user"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.val, func(t *testing.T) {
+			if got := parseTeamMembers(test.val); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseTeamMembers(%q) = %v, want %v", test.val, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAddTeamMember(t *testing.T) {
+	tests := []struct {
+		name     string
+		members  []team.Member
+		username string
+		want     []team.Member
+	}{
+		{name: "first member", username: "jane@example.com", want: []team.Member{{User: "jane@example.com", Role: "admin"}}},
+		{
+			name:     "new member",
+			members:  []team.Member{{User: "joe@example.com", Role: "user"}},
+			username: "jane@example.com",
+			want:     []team.Member{{User: "joe@example.com", Role: "user"}, {User: "jane@example.com", Role: "admin"}},
+		},
+		{
+			name:     "role of an existing member",
+			members:  []team.Member{{User: "Jane@example.com", Role: "user"}},
+			username: "jane@example.com",
+			want:     []team.Member{{User: "Jane@example.com", Role: "admin"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := addTeamMember(test.members, test.username, "admin"); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("addTeamMember() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRemoveTeamMember(t *testing.T) {
+	tests := []struct {
+		name     string
+		members  []team.Member
+		username string
+		want     []team.Member
+		wantErr  string
+	}{
+		{
+			name:     "one of the members",
+			members:  []team.Member{{User: "jane@example.com", Role: "admin"}, {User: "joe@example.com", Role: "user"}},
+			username: "JOE@example.com",
+			want:     []team.Member{{User: "jane@example.com", Role: "admin"}},
+		},
+		{
+			name:     "last member",
+			members:  []team.Member{{User: "jane@example.com", Role: "admin"}},
+			username: "jane@example.com",
+			want:     []team.Member{},
+		},
+		{
+			name:     "not a member",
+			members:  []team.Member{{User: "jane@example.com", Role: "admin"}},
+			username: "joe@example.com",
+			want:     []team.Member{{User: "jane@example.com", Role: "admin"}},
+			wantErr:  "User joe@example.com is not a member of the team",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := removeTeamMember(test.members, test.username)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("removeTeamMember() error = %v, want %q", err, test.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("removeTeamMember() error = %v", err)
+			}
+			// An empty list has to be sent to remove the last member, nil would leave the members unchanged.
+			if got == nil || !reflect.DeepEqual(got, test.want) {
+				t.Errorf("removeTeamMember() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTeamTableRows(t *testing.T) {
+	ops := team.Team{
+		ID:          "t1",
+		Name:        "ops",
+		Description: "on call",
+		Members:     []team.Member{{User: "jane@example.com", Role: "admin"}, {User: "joe@example.com", Role: "user"}},
+	}
+
+	wantTeams := [][]string{{"t1", "ops", "2", "on call"}, {"t2", "dev", "0", ""}}
+	if got := (teamList{ops, {ID: "t2", Name: "dev"}}).tableRows(); !reflect.DeepEqual(got, wantTeams) {
+		t.Errorf("teamList.tableRows() = %v, want %v", got, wantTeams)
+	}
+
+	wantMembers := [][]string{{"jane@example.com", "admin"}, {"joe@example.com", "user"}}
+	if got := teamMemberTable(ops).tableRows(); !reflect.DeepEqual(got, wantMembers) {
+		t.Errorf("teamMemberTable.tableRows() = %v, want %v", got, wantMembers)
+	}
+}
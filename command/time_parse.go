@@ -0,0 +1,86 @@
+package command
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// localTimeLayouts are the layouts accepted for times given without a zone; they are read in the local zone.
+var localTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+var dayDurationPattern = regexp.MustCompile(`^(\d+)([dw])(.*)$`)
+
+// parseTimeArg parses a time given on the command line relative to now. Accepted forms are
+// "now", "today", "tomorrow", relative offsets such as +2h, -30m or +1d12h, RFC3339 times,
+// local times such as "2006-01-02 15:04" or "2006-01-02", and a time of day such as 15:04.
+func parseTimeArg(val string, now time.Time) (time.Time, error) {
+	val = strings.TrimSpace(val)
+	switch strings.ToLower(val) {
+	case "now":
+		return now, nil
+	case "today":
+		return startOfDay(now), nil
+	case "tomorrow":
+		return startOfDay(now).AddDate(0, 0, 1), nil
+	}
+	if strings.HasPrefix(val, "+") || strings.HasPrefix(val, "-") {
+		offset, err := parseOffset(val[1:])
+		if err != nil {
+			return time.Time{}, errors.New("Invalid relative time " + val + ", use a value such as +2h, -30m or +1d")
+		}
+		if val[0] == '-' {
+			offset = -offset
+		}
+		return now.Add(offset), nil
+	}
+	if t, err := time.Parse(time.RFC3339, val); err == nil {
+		return t, nil
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, val, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.ParseInLocation("15:04", val, now.Location()); err == nil {
+		return startOfDay(now).Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute), nil
+	}
+	return time.Time{}, errors.New("Invalid time " + val + ", use now, a relative time such as +2h, " +
+		"a local time such as \"2006-01-02 15:04\" or an RFC3339 time")
+}
+
+// parseOffset parses a duration that may start with a number of days or weeks, such as 1d12h or 2w.
+func parseOffset(val string) (time.Duration, error) {
+	var offset time.Duration
+	if match := dayDurationPattern.FindStringSubmatch(val); match != nil {
+		count, err := strconv.Atoi(match[1])
+		if err != nil {
+			return 0, err
+		}
+		offset = time.Duration(count) * 24 * time.Hour
+		if match[2] == "w" {
+			offset *= 7
+		}
+		if match[3] == "" {
+			return offset, nil
+		}
+		val = match[3]
+	}
+	duration, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, err
+	}
+	return offset + duration, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
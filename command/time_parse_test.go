@@ -0,0 +1,79 @@
+package command
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeArg(t *testing.T) {
+	zone := time.FixedZone("UTC+3", 3*60*60)
+	now := time.Date(2026, 10, 19, 14, 30, 15, 0, zone)
+	tests := []struct {
+		val     string
+		want    time.Time
+		wantErr bool
+	}{
+		{val: "now", want: now},
+		{val: " NOW ", want: now},
+		{val: "today", want: time.Date(2026, 10, 19, 0, 0, 0, 0, zone)},
+		{val: "tomorrow", want: time.Date(2026, 10, 20, 0, 0, 0, 0, zone)},
+		{val: "+2h", want: now.Add(2 * time.Hour)},
+		{val: "-30m", want: now.Add(-30 * time.Minute)},
+		{val: "+1d12h", want: now.Add(36 * time.Hour)},
+		{val: "+2w", want: now.Add(14 * 24 * time.Hour)},
+		{val: "-1d", want: now.Add(-24 * time.Hour)},
+		{val: "2026-10-21T09:00:00Z", want: time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC)},
+		{val: "2026-10-21T09:00:00", want: time.Date(2026, 10, 21, 9, 0, 0, 0, zone)},
+		{val: "2026-10-21 09:00", want: time.Date(2026, 10, 21, 9, 0, 0, 0, zone)},
+		{val: "2026-10-21", want: time.Date(2026, 10, 21, 0, 0, 0, 0, zone)},
+		{val: "09:15", want: time.Date(2026, 10, 19, 9, 15, 0, 0, zone)},
+		{val: "+2", wantErr: true},
+		{val: "+1x", wantErr: true},
+		{val: "yesterday", wantErr: true},
+		{val: "2026-13-01", wantErr: true},
+		{val: "", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.val, func(t *testing.T) {
+			got, err := parseTimeArg(test.val, now)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("parseTimeArg(%q) = %s, want an error", test.val, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTimeArg(%q) returned %v", test.val, err)
+			}
+			if !got.Equal(test.want) {
+				t.Errorf("parseTimeArg(%q) = %s, want %s", test.val, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseOffset(t *testing.T) {
+	tests := []struct {
+		val     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{val: "90m", want: 90 * time.Minute},
+		{val: "1d", want: 24 * time.Hour},
+		{val: "1w", want: 7 * 24 * time.Hour},
+		{val: "2d6h30m", want: 54*time.Hour + 30*time.Minute},
+		{val: "d", wantErr: true},
+		{val: "", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.val, func(t *testing.T) {
+			got, err := parseOffset(test.val)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseOffset(%q) returned %v, want error %t", test.val, err, test.wantErr)
+			}
+			if got != test.want && !test.wantErr {
+				t.Errorf("parseOffset(%q) = %s, want %s", test.val, got, test.want)
+			}
+		})
+	}
+}
@@ -1,18 +1,69 @@
 package command
 
 import (
-	gcli "github.com/codegangsta/cli"
-	"github.com/opsgenie/opsgenie-go-sdk/userv2"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"log"
-	"bytes"
-	"strconv"
+	"strings"
 	"time"
+
+	gcli "github.com/codegangsta/cli"
+	ogcli "github.com/opsgenie/opsgenie-go-sdk/client"
+	"github.com/opsgenie/opsgenie-go-sdk/userv2"
+	yaml "gopkg.in/yaml.v2"
 )
 
-// ListUsersAction retrieves users from OpsGenie.
+// userColumn is a field of the user export, named as in the header of the CSV output.
+type userColumn struct {
+	name  string
+	value func(user userv2.User) interface{}
+}
+
+// userColumns lists the exportable fields in the default column order.
+var userColumns = []userColumn{
+	{"id", func(u userv2.User) interface{} { return u.ID }},
+	{"blocked", func(u userv2.User) interface{} { return u.Blocked }},
+	{"verified", func(u userv2.User) interface{} { return u.Verified }},
+	{"username", func(u userv2.User) interface{} { return u.Username }},
+	{"fullname", func(u userv2.User) interface{} { return u.FullName }},
+	{"roleId", func(u userv2.User) interface{} { return u.Role.ID }},
+	{"roleName", func(u userv2.User) interface{} { return u.Role.Name }},
+	{"timezone", func(u userv2.User) interface{} { return u.TimeZone }},
+	{"locale", func(u userv2.User) interface{} { return u.Locale }},
+	{"country", func(u userv2.User) interface{} { return u.UserAddress.Country }},
+	{"state", func(u userv2.User) interface{} { return u.UserAddress.State }},
+	{"city", func(u userv2.User) interface{} { return u.UserAddress.City }},
+	{"line", func(u userv2.User) interface{} { return u.UserAddress.Line }},
+	{"zipcode", func(u userv2.User) interface{} { return u.UserAddress.ZipCode }},
+	{"createdAt", func(u userv2.User) interface{} { return formatUserTime(u.CreatedAt) }},
+	{"mutedUntil", func(u userv2.User) interface{} { return formatUserTime(u.MutedUntil) }},
+}
+
+// ExportUsersAction retrieves users from OpsGenie and writes them as CSV, JSON or YAML.
 func ExportUsersAction(c *gcli.Context) {
+	columns, err := grabUserColumns(c)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	outputFormat := strings.ToLower(c.String("output-format"))
+	if outputFormat != "csv" && outputFormat != "json" && outputFormat != "yaml" {
+		fmt.Printf("Invalid output format %s, specify one of csv, json or yaml\n", outputFormat)
+		os.Exit(1)
+	}
+
+	expandColumns, err := grabExpandColumns(c)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+
 	cli, err := NewUserClient(c)
 	if err != nil {
 		os.Exit(1)
@@ -20,17 +71,77 @@ func ExportUsersAction(c *gcli.Context) {
 
 	printVerboseMessage("List users request prepared from flags, sending request to OpsGenie..")
 
+	users, err := listAllUsers(cli, generateListUsersRequest(c))
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	var expansions map[string]*userExpansion
+	if len(expandColumns) > 0 {
+		expander, err := newUserExpander(c, cli, expandColumns)
+		if err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
+		}
+		printVerboseMessage(fmt.Sprintf("Expanding %d users with %d workers..", len(users), expander.workers))
+		expansions = expander.expand(users, expandColumns)
+		failed := 0
+		for _, expansion := range expansions {
+			if len(expansion.errors) > 0 {
+				failed++
+			}
+		}
+		if failed > 0 {
+			// standard output may hold the export itself
+			fmt.Fprintf(os.Stderr, "WARNING: Could not expand %d users completely, see the expandErrors column\n", failed)
+		}
+	}
+	printVerboseMessage(fmt.Sprintf("Listed %d users, writing them as %s..", len(users), outputFormat))
+
+	output, path, err := openUsersOutput(c, outputFormat)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	err = writeUsers(output, outputFormat, columns, expandColumns, users, expansions)
+	if output != os.Stdout {
+		if closeErr := output.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Printf("Could not write users: %s\n", err.Error())
+		os.Exit(1)
+	}
+	if path != "" {
+		printVerboseMessage("The output file " + path + " has just been created.")
+	}
+}
+
+func generateListUsersRequest(c *gcli.Context) userv2.ListUsersRequest {
+	req := userv2.ListUsersRequest{}
+	req.Limit = 100
+
+	if val, success := getVal("query", c); success {
+		req.Query = val
+		printVerboseMessage("Listing users with given query.")
+	}
+
+	return req
+}
+
+// listAllUsers pages through the users matching the request.
+func listAllUsers(cli *ogcli.OpsGenieUserV2Client, req userv2.ListUsersRequest) ([]userv2.User, error) {
 	users := []userv2.User{}
 	var offset int = 0
 
-	req := generateListUsersRequest(c)
 	for {
 		req.Offset = offset
 		resp, err := cli.List(req)
 
 		if err != nil {
-			fmt.Printf("%s\n", err.Error())
-			os.Exit(1)
+			return nil, err
 		}
 		users = append(users, resp.Users...)
 
@@ -40,128 +151,651 @@ func ExportUsersAction(c *gcli.Context) {
 			offset = offset + req.Limit
 		}
 	}
-	writeCsv(c, users)
+	return users, nil
+}
 
+// grabUserColumns returns the columns given with --columns in the given order, or all columns by default.
+func grabUserColumns(c *gcli.Context) ([]userColumn, error) {
+	val, success := getVal("columns", c)
+	if !success {
+		return userColumns, nil
+	}
+	var columns []userColumn
+	for _, name := range strings.Split(val, ",") {
+		column, err := findUserColumn(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+func findUserColumn(name string) (userColumn, error) {
+	var names []string
+	for _, column := range userColumns {
+		if strings.EqualFold(column.name, name) {
+			return column, nil
+		}
+		names = append(names, column.name)
+	}
+	return userColumn{}, errors.New("Unknown column " + name + ", available columns are: " + strings.Join(names, ","))
 }
 
-func createFile(p string) *os.File{
-	f, err := os.Create(p)
+/*
+The 'openUsersOutput' function opens the file given with --output, or standard
+output when it is "-". If --output is not given, the file is named after the
+output format and created under --destinationPath or the working directory.
+*/
+func openUsersOutput(c *gcli.Context, outputFormat string) (*os.File, string, error) {
+	path, success := getVal("output", c)
+	if success && path == "-" {
+		return os.Stdout, "", nil
+	}
+	if !success {
+		dir, success := getVal("destinationPath", c)
+		if !success {
+			var err error
+			if dir, err = os.Getwd(); err != nil {
+				return nil, "", err
+			}
+		}
+		path = dir + string(os.PathSeparator) + "result." + outputFormat
+	}
+	file, err := os.Create(path)
 	if err != nil {
-		log.Fatal("Cannot create file", err)
+		return nil, "", errors.New("Cannot create file " + path + ". " + err.Error())
 	}
-	return f
+	return file, path, nil
 }
 
-func getDestinationPath(c *gcli.Context) string {
-	var destinationPath string = "."
-	val, success := getVal("destinationPath", c)
+func writeUsers(w io.Writer, outputFormat string, columns []userColumn, expandColumns []expandColumn,
+	users []userv2.User, expansions map[string]*userExpansion) error {
+	var headers []string
+	for _, column := range columns {
+		headers = append(headers, column.name)
+	}
+	for _, column := range expandColumns {
+		headers = append(headers, column.name)
+	}
+	records := []yaml.MapSlice{}
+	for _, user := range users {
+		record := yaml.MapSlice{}
+		for _, column := range columns {
+			record = append(record, yaml.MapItem{Key: column.name, Value: column.value(user)})
+		}
+		for _, column := range expandColumns {
+			record = append(record, yaml.MapItem{Key: column.name, Value: column.value(expansions[user.ID])})
+		}
+		records = append(records, record)
+	}
 
-	if success {
-		destinationPath = val
-	} else {
-		dir, err := os.Getwd()
+	switch outputFormat {
+	case "json", "yaml":
+		var output string
+		var err error
+		if outputFormat == "yaml" {
+			output, err = resultToYAML(records)
+		} else {
+			output, err = resultToJSON(orderedRecords(records), true)
+		}
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
-		destinationPath = dir
+		_, err = fmt.Fprintf(w, "%s\n", strings.TrimSuffix(output, "\n"))
+		return err
+	default:
+		return writeUsersCsv(w, headers, records)
 	}
-	return destinationPath
 }
 
-func generateListUsersRequest(c *gcli.Context) (userv2.ListUsersRequest) {
-	req := userv2.ListUsersRequest{}
-	req.Limit = 100
+// orderedRecord keeps the column order of a record when it is marshalled into JSON.
+type orderedRecord yaml.MapSlice
 
-	if val, success := getVal("query", c); success {
-		req.Query = val;
-		printVerboseMessage("Listing users with given query.")
+func (r orderedRecord) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, item := range r {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key, err := json.Marshal(fmt.Sprintf("%v", item.Key))
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+func orderedRecords(records []yaml.MapSlice) []orderedRecord {
+	ordered := make([]orderedRecord, len(records))
+	for i, record := range records {
+		ordered[i] = orderedRecord(record)
+	}
+	return ordered
+}
+
+func writeUsersCsv(w io.Writer, headers []string, records []yaml.MapSlice) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+	for _, record := range records {
+		row := make([]string, len(record))
+		for i, item := range record {
+			if value, ok := item.Value.(csvValue); ok {
+				row[i] = value.csvString()
+			} else {
+				row[i] = formatLeaf(item.Value)
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatUserTime formats timestamps in ISO 8601, leaving unset ones empty.
+func formatUserTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// importedUser holds the managed attributes of a user as given in an import file.
+// Only the attributes whose columns are present in the file are compared and updated, and blank cells leave
+// the attribute unchanged.
+type importedUser struct {
+	line   int
+	values map[string]string
+}
+
+// importedUserColumns are the user attributes importUsers manages. Other columns, such as id or createdAt, are ignored.
+var importedUserColumns = []string{"fullname", "roleName", "timezone", "locale", "country", "state", "city", "line", "zipcode"}
+
+var addressColumns = []string{"country", "state", "city", "line", "zipcode"}
+
+// ImportUsersAction creates and updates users at OpsGenie from a CSV file in the exportUsers layout.
+// With --prune and --yes, users missing from the file are blocked. With --dry-run the changes are only printed.
+func ImportUsersAction(c *gcli.Context) {
+	path, success := getVal("file", c)
+	if !success {
+		fmt.Printf("The CSV file to import must be provided with --file\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	imported, columns, err := readUsersCsv(path)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	dryRun := c.IsSet("dry-run")
+	prune := c.IsSet("prune")
+
+	cli, err := NewUserClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+	printVerboseMessage("List users request prepared, sending request to OpsGenie..")
+	users, err := listAllUsers(cli, userv2.ListUsersRequest{Limit: 100})
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	existing := map[string]userv2.User{}
+	for _, user := range users {
+		existing[strings.ToLower(user.Username)] = user
+	}
+	var pruned []userv2.User
+	if prune {
+		for _, user := range users {
+			if _, inFile := imported[strings.ToLower(user.Username)]; !inFile && !user.Blocked {
+				pruned = append(pruned, user)
+			}
+		}
+		if len(pruned) > 0 && !dryRun && !c.IsSet("yes") {
+			for _, user := range pruned {
+				fmt.Printf("- block %s\n", user.Username)
+			}
+			fmt.Printf("%d users would be blocked by --prune, run again with --yes to import and block them\n", len(pruned))
+			os.Exit(1)
+		}
+	}
+
+	failed := false
+	for _, username := range sortedImportedUsernames(imported) {
+		entry := imported[username]
+		user, exists := existing[username]
+		if !exists {
+			fmt.Printf("+ create %s\n", entry.values["username"])
+			for _, column := range importedUserColumns {
+				if val, ok := entry.values[column]; ok && val != "" {
+					fmt.Printf("    %s: %q\n", column, val)
+				}
+			}
+			if dryRun {
+				continue
+			}
+			if _, err := cli.Create(newCreateUserRequest(entry)); err != nil {
+				fmt.Printf("Could not create user %s: %s\n", entry.values["username"], err.Error())
+				failed = true
+			}
+			continue
+		}
+
+		changed := changedUserColumns(user, entry, columns)
+		if len(changed) == 0 {
+			printVerboseMessage("User " + user.Username + " is up to date")
+			continue
+		}
+		fmt.Printf("~ update %s\n", user.Username)
+		for _, name := range changed {
+			column, _ := findUserColumn(name)
+			fmt.Printf("    %s: %q -> %q\n", column.name, formatLeaf(column.value(user)), entry.values[column.name])
+		}
+		if dryRun {
+			continue
+		}
+		if _, err := cli.Update(newUpdateUserRequest(user, entry, changed)); err != nil {
+			fmt.Printf("Could not update user %s: %s\n", user.Username, err.Error())
+			failed = true
+		}
+	}
+
+	blocked := true
+	for _, user := range pruned {
+		fmt.Printf("- block %s\n", user.Username)
+		if dryRun {
+			continue
+		}
+		req := userv2.UpdateUserRequest{Identifier: &userv2.Identifier{ID: user.ID}, Blocked: &blocked}
+		if _, err := cli.Update(req); err != nil {
+			fmt.Printf("Could not block user %s: %s\n", user.Username, err.Error())
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// readUsersCsv reads the users of an import file by lowercased username, together with the managed columns present in its header.
+func readUsersCsv(path string) (map[string]importedUser, []string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, errors.New("Could not read " + path + ". " + err.Error())
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.New("Could not read the header of " + path + ". " + err.Error())
+	}
+	headerIndex := map[string]int{}
+	for i, header := range headers {
+		header = strings.TrimSpace(header)
+		column, err := findUserColumn(header)
+		if err != nil {
+			// the columns of exportUsers --expand are not imported
+			if !isExpandColumn(header) {
+				fmt.Fprintf(os.Stderr, "WARNING: Ignoring the unknown column %s of %s\n", header, path)
+			}
+			continue
+		}
+		headerIndex[column.name] = i
+	}
+	if _, ok := headerIndex["username"]; !ok {
+		return nil, nil, errors.New("Invalid header in " + path + ". The username column is required")
+	}
+	var columns []string
+	for _, column := range importedUserColumns {
+		if _, ok := headerIndex[column]; ok {
+			columns = append(columns, column)
+		}
+	}
+
+	users := map[string]importedUser{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.New("Could not read " + path + ". " + err.Error())
+		}
+		values := map[string]string{}
+		for name, i := range headerIndex {
+			values[name] = strings.TrimSpace(record[i])
+		}
+		username := strings.ToLower(values["username"])
+		if username == "" {
+			return nil, nil, fmt.Errorf("Missing username at line %d of %s", line, path)
+		}
+		if previous, duplicate := users[username]; duplicate {
+			return nil, nil, fmt.Errorf("User %s is given at both line %d and line %d of %s", values["username"], previous.line, line, path)
+		}
+		users[username] = importedUser{line: line, values: values}
+	}
+	return users, columns, nil
+}
+
+func sortedImportedUsernames(imported map[string]importedUser) []string {
+	usernames := map[string]string{}
+	for username := range imported {
+		usernames[username] = ""
+	}
+	return sortedKeys(usernames)
+}
+
+// changedUserColumns returns the managed columns whose values in the file differ from the user at OpsGenie.
+// Blank cells are not changes.
+func changedUserColumns(user userv2.User, entry importedUser, columns []string) []string {
+	var changed []string
+	for _, name := range columns {
+		column, _ := findUserColumn(name)
+		if val := entry.values[name]; val != "" && formatLeaf(column.value(user)) != val {
+			changed = append(changed, name)
+		}
 	}
+	return changed
+}
 
+func newCreateUserRequest(entry importedUser) userv2.CreateUserRequest {
+	values := entry.values
+	req := userv2.CreateUserRequest{
+		Username: values["username"],
+		FullName: values["fullname"],
+		TimeZone: values["timezone"],
+		Locale:   values["locale"],
+	}
+	if values["roleName"] != "" {
+		req.Role = &userv2.UserRoleRequest{RoleName: values["roleName"]}
+	}
+	if hasAnyValue(values, addressColumns) {
+		req.UserAddress = newUserAddressRequest(values)
+	}
 	return req
 }
 
-func writeCsv(c *gcli.Context, users []userv2.User) {
-	csv, err := createCsv(users)
+func newUpdateUserRequest(user userv2.User, entry importedUser, changed []string) userv2.UpdateUserRequest {
+	values := entry.values
+	req := userv2.UpdateUserRequest{Identifier: &userv2.Identifier{ID: user.ID}}
+	addressChanged := false
+	for _, column := range changed {
+		switch column {
+		case "fullname":
+			req.FullName = values[column]
+		case "roleName":
+			req.Role = &userv2.UserRoleRequest{RoleName: values[column]}
+		case "timezone":
+			req.TimeZone = values[column]
+		case "locale":
+			req.Locale = values[column]
+		default:
+			addressChanged = true
+		}
+	}
+	if addressChanged {
+		// The address is replaced as a whole, so the columns missing from the file or blank keep their current values.
+		address := map[string]string{
+			"country": user.UserAddress.Country,
+			"state":   user.UserAddress.State,
+			"city":    user.UserAddress.City,
+			"line":    user.UserAddress.Line,
+			"zipcode": user.UserAddress.ZipCode,
+		}
+		for _, column := range addressColumns {
+			if val := values[column]; val != "" {
+				address[column] = val
+			}
+		}
+		req.UserAddress = newUserAddressRequest(address)
+	}
+	return req
+}
+
+func newUserAddressRequest(values map[string]string) *userv2.UserAddressRequest {
+	return &userv2.UserAddressRequest{
+		Country: values["country"],
+		State:   values["state"],
+		City:    values["city"],
+		Line:    values["line"],
+		ZipCode: values["zipcode"],
+	}
+}
+
+func hasAnyValue(values map[string]string, keys []string) bool {
+	for _, key := range keys {
+		if values[key] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ListUsersAction retrieves all users matching the --query parameter from OpsGenie.
+func ListUsersAction(c *gcli.Context) {
+	cli, err := NewUserClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	printVerboseMessage("List users request prepared from flags, sending request to OpsGenie..")
 
+	users, err := listAllUsers(cli, generateListUsersRequest(c))
 	if err != nil {
-		log.Fatal(err)
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Users listed successfully.")
+	printResult(c, userList(users))
+}
+
+// GetUserAction retrieves the specified user from OpsGenie.
+func GetUserAction(c *gcli.Context) {
+	cli, err := NewUserClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	req := userv2.GetUserRequest{Identifier: grabUserIdentifier(c)}
+
+	printVerboseMessage("Get user request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.Get(req)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("Got user successfully.")
+	printResult(c, resp.User)
+}
+
+// CreateUserAction creates a user at OpsGenie.
+func CreateUserAction(c *gcli.Context) {
+	cli, err := NewUserClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	req := userv2.CreateUserRequest{}
+	if val, success := getVal("username", c); success {
+		req.Username = val
 	} else {
-		destinationPath := getDestinationPath(c)
+		fmt.Printf("Username of the user must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	if val, success := getVal("fullName", c); success {
+		req.FullName = val
+	}
+	if val, success := getVal("role", c); success {
+		req.Role = &userv2.UserRoleRequest{RoleName: val}
+	}
+	if val, success := getVal("timezone", c); success {
+		req.TimeZone = val
+	}
+	if val, success := getVal("locale", c); success {
+		req.Locale = val
+	}
+	if val, success := getVal("skypeUsername", c); success {
+		req.SkypeUsername = val
+	}
+	if val, success := getVal("tags", c); success {
+		req.Tags = strings.Split(val, ",")
+	}
+	req.UserAddress = grabUserAddress(c, nil)
+	req.InvitationDisabled = c.IsSet("invitationDisabled")
 
-		file := createFile(destinationPath + "/result.csv")
-		defer file.Close()
+	printVerboseMessage("Create user request prepared from flags, sending request to OpsGenie..")
 
-		_, err := file.Write(csv)
+	resp, err := cli.Create(req)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+	printVerboseMessage("User will be created. RequestID: " + resp.RequestID)
+	fmt.Printf("id=%s\n", resp.ID)
+}
 
+// UpdateUserAction updates the specified user at OpsGenie. Only the given attributes are changed.
+func UpdateUserAction(c *gcli.Context) {
+	cli, err := NewUserClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	req := userv2.UpdateUserRequest{Identifier: grabUserIdentifier(c)}
+	if val, success := getVal("fullName", c); success {
+		req.FullName = val
+	}
+	if val, success := getVal("role", c); success {
+		req.Role = &userv2.UserRoleRequest{RoleName: val}
+	}
+	if val, success := getVal("timezone", c); success {
+		req.TimeZone = val
+	}
+	if val, success := getVal("locale", c); success {
+		req.Locale = val
+	}
+	if val, success := getVal("skypeUsername", c); success {
+		req.SkypeUsername = val
+	}
+	if val, success := getVal("tags", c); success {
+		req.Tags = strings.Split(val, ",")
+	}
+	if hasAddressFlag(c) {
+		// The address is replaced as a whole, so the current one is needed to keep the fields that are not given.
+		resp, err := cli.Get(userv2.GetUserRequest{Identifier: req.Identifier})
 		if err != nil {
-			log.Fatal(err)
-		} else {
-			printVerboseMessage("The output file named result.csv has just been created.")
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
 		}
+		req.UserAddress = grabUserAddress(c, &resp.User.UserAddress)
+	}
+
+	printVerboseMessage("Update user request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.Update(req)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
 	}
+	printVerboseMessage("User will be updated. RequestID: " + resp.RequestID)
+	fmt.Println("RequestID: " + resp.RequestID)
 }
 
-func createCsv(users []userv2.User) ([]byte, error){
-	var buf bytes.Buffer
-	headers := []string {"id", "blocked", "verified", "username", "fullname", "roleId", "roleName", "timezone",
-							"locale", "country", "state", "city", "line", "zipcode", "createdAt", "mutedUntil"}
+// DeleteUserAction deletes the specified user at OpsGenie.
+func DeleteUserAction(c *gcli.Context) {
+	cli, err := NewUserClient(c)
+	if err != nil {
+		os.Exit(1)
+	}
 
-	writeHeaders(&buf, headers)
-	buf.WriteString("\n")
+	req := userv2.DeleteUserRequest{Identifier: grabUserIdentifier(c)}
 
-	for _, user := range users {
-		extractFields(&buf, user)
-		buf.WriteString("\n")
+	printVerboseMessage("Delete user request prepared from flags, sending request to OpsGenie..")
+
+	resp, err := cli.Delete(req)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
 	}
+	printVerboseMessage("User will be deleted. RequestID: " + resp.RequestID)
+	fmt.Println("RequestID: " + resp.RequestID)
+}
 
-	return buf.Bytes(), nil
+// grabUserIdentifier returns the user given by --id or --username, exiting when neither is provided.
+func grabUserIdentifier(c *gcli.Context) *userv2.Identifier {
+	identifier := &userv2.Identifier{}
+	if val, success := getVal("id", c); success {
+		identifier.ID = val
+	}
+	if val, success := getVal("username", c); success {
+		identifier.Username = val
+	}
+	if identifier.ID == "" && identifier.Username == "" {
+		fmt.Printf("Either id or username of the user must be provided\n")
+		gcli.ShowCommandHelp(c, c.Command.Name)
+		os.Exit(1)
+	}
+	return identifier
 }
 
-func writeHeaders(buf *bytes.Buffer, headers []string){
-	for index, header := range headers {
-		buf.WriteString(header)
-		if index < len(headers) -1 {
-			buf.WriteString(",")
+func hasAddressFlag(c *gcli.Context) bool {
+	for _, name := range addressColumns {
+		if c.IsSet(name) {
+			return true
 		}
 	}
+	return false
 }
 
-func extractFields(buf *bytes.Buffer, user userv2.User){
-	buf.WriteString(user.ID)
-	buf.WriteString(",")
-	buf.WriteString(strconv.FormatBool(user.Blocked))
-	buf.WriteString(",")
-	buf.WriteString(strconv.FormatBool(user.Verified))
-	buf.WriteString(",")
-	buf.WriteString(user.Username)
-	buf.WriteString(",")
-	buf.WriteString(user.FullName)
-	buf.WriteString(",")
-	buf.WriteString(user.Role.ID)
-	buf.WriteString(",")
-	buf.WriteString(user.Role.Name)
-	buf.WriteString(",")
-	buf.WriteString(user.TimeZone)
-	buf.WriteString(",")
-	buf.WriteString(user.Locale)
-	buf.WriteString(",")
-	buf.WriteString(user.UserAddress.Country)
-	buf.WriteString(",")
-	buf.WriteString(user.UserAddress.State)
-	buf.WriteString(",")
-	buf.WriteString(user.UserAddress.City)
-	buf.WriteString(",")
-	buf.WriteString(user.UserAddress.Line)
-	buf.WriteString(",")
-	buf.WriteString(user.UserAddress.ZipCode)
-	buf.WriteString(",")
-	buf.WriteString(user.CreatedAt.Format(time.RFC822))
-	buf.WriteString(",")
-	if !user.MutedUntil.IsZero(){
-		buf.WriteString(user.MutedUntil.Format(time.RFC822))
-	} else {
-	buf.WriteString("")
+// grabUserAddress builds the address from the flags on top of the current address, if any.
+func grabUserAddress(c *gcli.Context, current *userv2.UserAddress) *userv2.UserAddressRequest {
+	if !hasAddressFlag(c) {
+		return nil
+	}
+	values := map[string]string{}
+	if current != nil {
+		values["country"] = current.Country
+		values["state"] = current.State
+		values["city"] = current.City
+		values["line"] = current.Line
+		values["zipcode"] = current.ZipCode
+	}
+	for _, name := range addressColumns {
+		if val, success := getVal(name, c); success {
+			values[name] = val
+		}
 	}
+	return newUserAddressRequest(values)
 }
 
+// userList prints users with their main attributes in table output format.
+type userList []userv2.User
 
+func (l userList) tableHeaders() []string {
+	return []string{"id", "username", "fullname", "role", "timezone", "blocked", "verified"}
+}
+
+func (l userList) tableRows() [][]string {
+	var rows [][]string
+	for _, u := range l {
+		rows = append(rows, []string{u.ID, u.Username, u.FullName, u.Role.Name, u.TimeZone,
+			formatLeaf(u.Blocked), formatLeaf(u.Verified)})
+	}
+	return rows
+}
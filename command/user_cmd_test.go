@@ -0,0 +1,349 @@
+package command
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk/userv2"
+)
+
+func TestGrabUserColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns string
+		want    []string
+		wantErr bool
+	}{
+		{name: "all columns by default", want: []string{"id", "blocked", "verified", "username", "fullname", "roleId", "roleName",
+			"timezone", "locale", "country", "state", "city", "line", "zipcode", "createdAt", "mutedUntil"}},
+		{name: "given order", columns: "username,id", want: []string{"username", "id"}},
+		{name: "case and spaces ignored", columns: "UserName, roleid", want: []string{"username", "roleId"}},
+		{name: "unknown column", columns: "username,email", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := map[string]string{}
+			if test.columns != "" {
+				flags["columns"] = test.columns
+			}
+			columns, err := grabUserColumns(newTestContext(t, flags))
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("grabUserColumns() returned no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, column := range columns {
+				got = append(got, column.name)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("grabUserColumns() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWriteUsers(t *testing.T) {
+	users := []userv2.User{
+		{
+			ID:        "1",
+			Username:  "jane@example.com",
+			FullName:  "Doe, Jane",
+			Verified:  true,
+			CreatedAt: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC),
+		},
+		{ID: "2", Username: "joe@example.com", Blocked: true},
+	}
+	users[0].Role.Name = "Admin"
+	users[0].UserAddress.City = "Izmir"
+	columns := []userColumn{}
+	for _, name := range []string{"username", "fullname", "verified", "roleName", "city", "createdAt"} {
+		column, err := findUserColumn(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		columns = append(columns, column)
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "csv",
+			want: "username,fullname,verified,roleName,city,createdAt\n" +
+				"jane@example.com,\"Doe, Jane\",true,Admin,Izmir,2026-10-19T08:00:00Z\n" +
+				"joe@example.com,,false,,,\n",
+		},
+		{
+			format: "json",
+			want: "[\n" +
+				"    {\n" +
+				"        \"username\": \"jane@example.com\",\n" +
+				"        \"fullname\": \"Doe, Jane\",\n" +
+				"        \"verified\": true,\n" +
+				"        \"roleName\": \"Admin\",\n" +
+				"        \"city\": \"Izmir\",\n" +
+				"        \"createdAt\": \"2026-10-19T08:00:00Z\"\n" +
+				"    },\n" +
+				"    {\n" +
+				"        \"username\": \"joe@example.com\",\n" +
+				"        \"fullname\": \"\",\n" +
+				"        \"verified\": false,\n" +
+				"        \"roleName\": \"\",\n" +
+				"        \"city\": \"\",\n" +
+				"        \"createdAt\": \"\"\n" +
+				"    }\n" +
+				"]\n",
+		},
+		{
+			format: "yaml",
+			want: "- username: jane@example.com\n" +
+				"  fullname: Doe, Jane\n" +
+				"  verified: true\n" +
+				"  roleName: Admin\n" +
+				"  city: Izmir\n" +
+				"  createdAt: \"2026-10-19T08:00:00Z\"\n" +
+				"- username: joe@example.com\n" +
+				"  fullname: \"\"\n" +
+				"  verified: false\n" +
+				"  roleName: \"\"\n" +
+				"  city: \"\"\n" +
+				"  createdAt: \"\"\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeUsers(&buf, test.format, columns, nil, users, nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != test.want {
+				t.Errorf("writeUsers(%s) =\n%s\nwant\n%s", test.format, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGrabUserIdentifier(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags map[string]string
+		want  userv2.Identifier
+	}{
+		{name: "id", flags: map[string]string{"id": "1"}, want: userv2.Identifier{ID: "1"}},
+		{name: "username", flags: map[string]string{"username": "jane@example.com"}, want: userv2.Identifier{Username: "jane@example.com"}},
+		{name: "both", flags: map[string]string{"id": "1", "username": "jane@example.com"}, want: userv2.Identifier{ID: "1", Username: "jane@example.com"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := grabUserIdentifier(newTestContext(t, test.flags)); *got != test.want {
+				t.Errorf("grabUserIdentifier() = %+v, want %+v", *got, test.want)
+			}
+		})
+	}
+}
+
+func TestGrabUserAddress(t *testing.T) {
+	current := &userv2.UserAddress{Country: "TR", State: "Izmir", City: "Izmir", Line: "Old street", ZipCode: "35000"}
+	tests := []struct {
+		name    string
+		flags   map[string]string
+		current *userv2.UserAddress
+		want    *userv2.UserAddressRequest
+	}{
+		{
+			name:    "no address flags",
+			flags:   map[string]string{"fullName": "Jane"},
+			current: current,
+			want:    nil,
+		},
+		{
+			name:  "new address",
+			flags: map[string]string{"city": "Ankara", "country": "TR"},
+			want:  &userv2.UserAddressRequest{Country: "TR", City: "Ankara"},
+		},
+		{
+			name:    "given fields replace the current ones",
+			flags:   map[string]string{"line": "New street", "zipcode": "35100"},
+			current: current,
+			want:    &userv2.UserAddressRequest{Country: "TR", State: "Izmir", City: "Izmir", Line: "New street", ZipCode: "35100"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := grabUserAddress(newTestContext(t, test.flags), test.current); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("grabUserAddress() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUserListTableRows(t *testing.T) {
+	users := userList{
+		{ID: "1", Username: "jane@example.com", FullName: "Jane Doe", TimeZone: "Europe/Istanbul", Verified: true},
+		{ID: "2", Username: "joe@example.com", Blocked: true},
+	}
+	users[0].Role.Name = "Admin"
+	want := [][]string{
+		{"1", "jane@example.com", "Jane Doe", "Admin", "Europe/Istanbul", "false", "true"},
+		{"2", "joe@example.com", "", "", "", "true", "false"},
+	}
+	if got := users.tableRows(); !reflect.DeepEqual(got, want) {
+		t.Errorf("tableRows() = %v, want %v", got, want)
+	}
+}
+
+func writeTestFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadUsersCsv(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		users   map[string]importedUser
+		columns []string
+		wantErr bool
+	}{
+		{
+			name:    "managed and ignored columns",
+			content: "id,username,fullname,roleName,city\n1,Jane@example.com, Jane Doe ,admin,\n",
+			users: map[string]importedUser{"jane@example.com": {line: 2, values: map[string]string{
+				"id": "1", "username": "Jane@example.com", "fullname": "Jane Doe", "roleName": "admin", "city": "",
+			}}},
+			columns: []string{"fullname", "roleName", "city"},
+		},
+		{
+			name:    "case insensitive header",
+			content: "USERNAME,Timezone\njohn@example.com,Europe/Istanbul\n",
+			users: map[string]importedUser{"john@example.com": {line: 2, values: map[string]string{
+				"username": "john@example.com", "timezone": "Europe/Istanbul",
+			}}},
+			columns: []string{"timezone"},
+		},
+		{
+			name:    "expand and unknown columns are ignored",
+			content: "username,fullname,teams,notificationRules,expandErrors,nickname\njane@example.com,Jane Doe,ops,,,jd\n",
+			users: map[string]importedUser{"jane@example.com": {line: 2, values: map[string]string{
+				"username": "jane@example.com", "fullname": "Jane Doe",
+			}}},
+			columns: []string{"fullname"},
+		},
+		{
+			name:    "username column is required",
+			content: "fullname\nJane Doe\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing username",
+			content: "username,fullname\n,Jane Doe\n",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate username",
+			content: "username\njane@example.com\nJANE@example.com\n",
+			wantErr: true,
+		},
+		{
+			name:    "wrong number of cells",
+			content: "username,fullname\njane@example.com\n",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users, columns, err := readUsersCsv(writeTestFile(t, "users.csv", test.content))
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("readUsersCsv() returned no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(users, test.users) {
+				t.Errorf("users = %+v, want %+v", users, test.users)
+			}
+			if !reflect.DeepEqual(columns, test.columns) {
+				t.Errorf("columns = %v, want %v", columns, test.columns)
+			}
+		})
+	}
+}
+
+func TestChangedUserColumns(t *testing.T) {
+	user := userv2.User{
+		Username:    "jane@example.com",
+		FullName:    "Jane Doe",
+		Role:        userv2.Role{Name: "admin"},
+		TimeZone:    "UTC",
+		UserAddress: userv2.UserAddress{City: "Istanbul"},
+	}
+	columns := []string{"fullname", "roleName", "timezone", "city"}
+	tests := []struct {
+		name   string
+		values map[string]string
+		want   []string
+	}{
+		{
+			name:   "unchanged",
+			values: map[string]string{"fullname": "Jane Doe", "roleName": "admin", "timezone": "UTC", "city": "Istanbul"},
+			want:   nil,
+		},
+		{
+			name:   "blank cells leave the attributes unchanged",
+			values: map[string]string{"fullname": "", "roleName": "", "timezone": "", "city": ""},
+			want:   nil,
+		},
+		{
+			name:   "changed",
+			values: map[string]string{"fullname": "Jane Smith", "roleName": "user", "timezone": "UTC", "city": "Ankara"},
+			want:   []string{"fullname", "roleName", "city"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := changedUserColumns(user, importedUser{values: test.values}, columns)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("changedUserColumns() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewUpdateUserRequest(t *testing.T) {
+	user := userv2.User{
+		ID:          "1",
+		UserAddress: userv2.UserAddress{Country: "TR", City: "Istanbul", ZipCode: "34000"},
+	}
+	entry := importedUser{values: map[string]string{"roleName": "user", "city": "Ankara", "zipcode": ""}}
+	req := newUpdateUserRequest(user, entry, []string{"roleName", "city"})
+
+	if req.Identifier == nil || req.Identifier.ID != "1" {
+		t.Errorf("Identifier = %+v, want id 1", req.Identifier)
+	}
+	if req.Role == nil || req.Role.RoleName != "user" {
+		t.Errorf("Role = %+v, want user", req.Role)
+	}
+	if req.FullName != "" {
+		t.Errorf("FullName = %q, want it unchanged", req.FullName)
+	}
+	want := &userv2.UserAddressRequest{Country: "TR", City: "Ankara", ZipCode: "34000"}
+	if !reflect.DeepEqual(req.UserAddress, want) {
+		t.Errorf("UserAddress = %+v, want %+v", req.UserAddress, want)
+	}
+}
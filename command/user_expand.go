@@ -0,0 +1,240 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	gcli "github.com/codegangsta/cli"
+	ogcli "github.com/opsgenie/opsgenie-go-sdk/client"
+	"github.com/opsgenie/opsgenie-go-sdk/contact"
+	"github.com/opsgenie/opsgenie-go-sdk/notificationv2"
+	"github.com/opsgenie/opsgenie-go-sdk/userv2"
+)
+
+const defaultExpandWorkers = 5
+
+// csvValue is implemented by the expanded values that hold several items, to print them in a single CSV cell.
+type csvValue interface {
+	csvString() string
+}
+
+type expandedTeam struct {
+	ID   string `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+}
+
+type expandedTeams []expandedTeam
+
+func (t expandedTeams) csvString() string {
+	var names []string
+	for _, team := range t {
+		names = append(names, team.Name)
+	}
+	return strings.Join(names, ";")
+}
+
+type expandedContact struct {
+	Method  string `json:"method" yaml:"method"`
+	To      string `json:"to" yaml:"to"`
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+}
+
+type expandedContacts []expandedContact
+
+func (c expandedContacts) csvString() string {
+	var contacts []string
+	for _, contact := range c {
+		contacts = append(contacts, contact.Method+":"+contact.To)
+	}
+	return strings.Join(contacts, ";")
+}
+
+type expandedRule struct {
+	Name       string `json:"name" yaml:"name"`
+	ActionType string `json:"actionType" yaml:"actionType"`
+	Enabled    bool   `json:"enabled" yaml:"enabled"`
+}
+
+type expandedRules []expandedRule
+
+func (r expandedRules) csvString() string {
+	var rules []string
+	for _, rule := range r {
+		rules = append(rules, rule.Name)
+	}
+	return strings.Join(rules, ";")
+}
+
+type expandErrors []string
+
+func (e expandErrors) csvString() string {
+	return strings.Join(e, ";")
+}
+
+// userExpansion holds the related data fetched for a user with --expand.
+type userExpansion struct {
+	teams    expandedTeams
+	contacts expandedContacts
+	rules    expandedRules
+	errors   expandErrors
+}
+
+// expandColumn is an extra column of the user export filled from the expanded data.
+type expandColumn struct {
+	name  string
+	value func(expansion *userExpansion) interface{}
+}
+
+var expandableColumns = map[string]expandColumn{
+	"teams":    {"teams", func(e *userExpansion) interface{} { return e.teams }},
+	"contacts": {"contacts", func(e *userExpansion) interface{} { return e.contacts }},
+	"rules":    {"notificationRules", func(e *userExpansion) interface{} { return e.rules }},
+}
+
+var expandErrorsColumn = expandColumn{"expandErrors", func(e *userExpansion) interface{} { return e.errors }}
+
+// isExpandColumn reports whether the header is one of the columns written by exportUsers --expand.
+func isExpandColumn(header string) bool {
+	if strings.EqualFold(header, expandErrorsColumn.name) {
+		return true
+	}
+	for _, column := range expandableColumns {
+		if strings.EqualFold(header, column.name) {
+			return true
+		}
+	}
+	return false
+}
+
+// grabExpandColumns returns the columns requested with --expand, followed by the column of the fetch errors.
+func grabExpandColumns(c *gcli.Context) ([]expandColumn, error) {
+	val, success := getVal("expand", c)
+	if !success {
+		return nil, nil
+	}
+	var columns []expandColumn
+	for _, name := range strings.Split(val, ",") {
+		column, ok := expandableColumns[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, errors.New("Unknown expand option " + name + ", specify any of teams, contacts or rules")
+		}
+		columns = append(columns, column)
+	}
+	return append(columns, expandErrorsColumn), nil
+}
+
+// userExpander fetches the related data of users with a pool of workers.
+type userExpander struct {
+	userCli         *ogcli.OpsGenieUserV2Client
+	contactCli      *ogcli.OpsGenieContactClient
+	notificationCli *ogcli.OpsGenieNotificationV2Client
+	workers         int
+}
+
+func newUserExpander(c *gcli.Context, userCli *ogcli.OpsGenieUserV2Client, columns []expandColumn) (*userExpander, error) {
+	expander := &userExpander{userCli: userCli, workers: defaultExpandWorkers}
+	if val, success := getVal("workers", c); success {
+		if _, err := fmt.Sscanf(val, "%d", &expander.workers); err != nil || expander.workers < 1 {
+			return nil, errors.New("Invalid workers " + val + ", it must be a positive number")
+		}
+	}
+	for _, column := range columns {
+		var err error
+		switch column.name {
+		case "contacts":
+			expander.contactCli, err = NewContactClient(c)
+		case "notificationRules":
+			expander.notificationCli, err = NewNotificationClient(c)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return expander, nil
+}
+
+// expand fetches the related data of every user. A failing fetch is recorded in the errors of that user only.
+func (e *userExpander) expand(users []userv2.User, columns []expandColumn) map[string]*userExpansion {
+	expansions := make(map[string]*userExpansion, len(users))
+	for _, user := range users {
+		expansions[user.ID] = &userExpansion{}
+	}
+
+	jobs := make(chan userv2.User)
+	var wg sync.WaitGroup
+	for i := 0; i < e.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for user := range jobs {
+				// Each worker writes only the expansion of the user it took, so no locking is needed.
+				e.expandUser(user, columns, expansions[user.ID])
+			}
+		}()
+	}
+	for _, user := range users {
+		jobs <- user
+	}
+	close(jobs)
+	wg.Wait()
+	return expansions
+}
+
+func (e *userExpander) expandUser(user userv2.User, columns []expandColumn, expansion *userExpansion) {
+	for _, column := range columns {
+		var err error
+		switch column.name {
+		case "teams":
+			expansion.teams, err = e.teams(user)
+		case "contacts":
+			expansion.contacts, err = e.contacts(user)
+		case "notificationRules":
+			expansion.rules, err = e.rules(user)
+		default:
+			continue
+		}
+		if err != nil {
+			message := column.name + ": " + err.Error()
+			printVerboseMessage("Could not expand user " + user.Username + " " + message)
+			expansion.errors = append(expansion.errors, message)
+		}
+	}
+}
+
+func (e *userExpander) teams(user userv2.User) (expandedTeams, error) {
+	resp, err := e.userCli.ListUserTeams(userv2.ListUserTeamsRequest{Identifier: &userv2.Identifier{ID: user.ID}})
+	if err != nil {
+		return nil, err
+	}
+	teams := expandedTeams{}
+	for _, team := range resp.Teams {
+		teams = append(teams, expandedTeam{ID: team.ID, Name: team.Name})
+	}
+	return teams, nil
+}
+
+func (e *userExpander) contacts(user userv2.User) (expandedContacts, error) {
+	resp, err := e.contactCli.List(contact.ListContactsRequest{UserID: user.ID})
+	if err != nil {
+		return nil, err
+	}
+	contacts := expandedContacts{}
+	for _, c := range resp.Contacts {
+		contacts = append(contacts, expandedContact{Method: c.Method, To: c.To, Enabled: c.Enabled})
+	}
+	return contacts, nil
+}
+
+func (e *userExpander) rules(user userv2.User) (expandedRules, error) {
+	resp, err := e.notificationCli.List(notificationv2.ListNotificationRulesRequest{UserIdentifier: user.ID})
+	if err != nil {
+		return nil, err
+	}
+	rules := expandedRules{}
+	for _, r := range resp.Rules {
+		rules = append(rules, expandedRule{Name: r.Name, ActionType: r.ActionType, Enabled: r.Enabled})
+	}
+	return rules, nil
+}
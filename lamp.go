@@ -21,7 +21,7 @@ var commonFlags = []gcli.Flag{
 	},
 	gcli.StringFlag{
 		Name:  "apiKey",
-		Usage: "API key used for authenticating API requests. If not given, the api key in the conf file is used",
+		Usage: "API key used for authenticating API requests, or env:VAR to read it from the environment variable VAR. If not given, the api key in the conf file is used",
 	},
 	gcli.StringFlag{
 		Name:  "user",
@@ -31,6 +31,30 @@ var commonFlags = []gcli.Flag{
 		Name:  "config",
 		Usage: "Configuration file path",
 	},
+	gcli.StringFlag{
+		Name:  "profile",
+		Usage: "Configuration profile to use. If not given, LAMP_PROFILE or the default section of the conf file is used",
+	},
+	gcli.StringFlag{
+		Name:  "region",
+		Usage: "OpsGenie region to send requests to: us, eu or sandbox. If not given, the region of the conf file or us is used. opsgenie.api.url overrides it",
+	},
+	gcli.BoolFlag{
+		Name:  "lenient",
+		Usage: "Print configuration problems as warnings instead of stopping the execution",
+	},
+}
+
+var outputFlags = []gcli.Flag{
+	gcli.StringFlag{
+		Name:  "output-format",
+		Value: "json",
+		Usage: "Prints the output in json, yaml or table formats",
+	},
+	gcli.BoolFlag{
+		Name:  "pretty",
+		Usage: "For more readable JSON output",
+	},
 }
 
 func createAlertCommand() gcli.Command {
@@ -1058,11 +1082,31 @@ func heartbeatCommand() gcli.Command {
 			Name:  "name",
 			Usage: "Name of the heartbeat on OpsGenie",
 		},
+		gcli.StringSliceFlag{
+			Name:  "if-file-newer-than",
+			Usage: "Sends the heartbeat only if the file was modified within the duration.\n\tSyntax: --if-file-newer-than /backups/latest.tar 26h or --if-file-newer-than /backups/latest.tar=26h",
+		},
+		gcli.StringSliceFlag{
+			Name:  "if-port-open",
+			Usage: "Sends the heartbeat only if a TCP connection can be made to host:port",
+		},
+		gcli.StringSliceFlag{
+			Name:  "if-http-ok",
+			Usage: "Sends the heartbeat only if a GET request to the URL returns a 2xx status",
+		},
+		gcli.StringSliceFlag{
+			Name:  "if-process-running",
+			Usage: "Sends the heartbeat only if a process with the given name is running",
+		},
+		gcli.StringSliceFlag{
+			Name:  "if-command",
+			Usage: "Sends the heartbeat only if the shell command exits successfully",
+		},
 	}
 	flags := append(commonFlags, commandFlags...)
 	cmd := gcli.Command{Name: "heartbeat",
 		Flags:            flags,
-		Usage:            "Sends heartbeat to OpsGenie",
+		Usage:            "Sends heartbeat to OpsGenie. Exits with status 3 without sending it when an --if-* condition does not hold",
 		Action: func(c *gcli.Context) error {
 			command.HeartbeatAction(c)
 			return nil
@@ -1070,15 +1114,131 @@ func heartbeatCommand() gcli.Command {
 	return cmd
 }
 
+func heartbeatsCommand() gcli.Command {
+	nameFlag := gcli.StringFlag{
+		Name:  "name",
+		Usage: "Name of the heartbeat on OpsGenie",
+	}
+	definitionFlags := []gcli.Flag{
+		nameFlag,
+		gcli.StringFlag{
+			Name:  "interval",
+			Usage: "Amount of time OpsGenie waits for a ping before creating an alert",
+		},
+		gcli.StringFlag{
+			Name:  "intervalUnit",
+			Usage: "Unit of the interval. Values: minutes, hours, days",
+		},
+		gcli.StringFlag{
+			Name:  "description",
+			Usage: "Description of the heartbeat",
+		},
+		gcli.StringFlag{
+			Name:  "ownerTeam",
+			Usage: "Name of the team that owns the heartbeat",
+		},
+	}
+	listFlags := append(commonFlags, outputFlags...)
+	getFlags := append(append(commonFlags, nameFlag), outputFlags...)
+	createFlags := append(append(commonFlags, definitionFlags...), gcli.BoolFlag{
+		Name:  "disabled",
+		Usage: "Creates the heartbeat in disabled state",
+	})
+	updateFlags := append(commonFlags, definitionFlags...)
+	nameFlags := append(commonFlags, nameFlag)
+
+	cmd := gcli.Command{Name: "heartbeats",
+		Usage: "Manages OpsGenie heartbeats",
+		Subcommands: []gcli.Command{
+			{
+				Name:  "list",
+				Flags: listFlags,
+				Usage: "Lists the heartbeats at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.ListHeartbeatsAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "get",
+				Flags: getFlags,
+				Usage: "Gets a heartbeat from OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.GetHeartbeatAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "create",
+				Flags: createFlags,
+				Usage: "Creates a heartbeat at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.CreateHeartbeatAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "update",
+				Flags: updateFlags,
+				Usage: "Updates a heartbeat at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.UpdateHeartbeatAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "delete",
+				Flags: nameFlags,
+				Usage: "Deletes a heartbeat at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.DeleteHeartbeatAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "enable",
+				Flags: nameFlags,
+				Usage: "Enables a heartbeat at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.EnableHeartbeatAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "disable",
+				Flags: nameFlags,
+				Usage: "Disables a heartbeat at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.DisableHeartbeatAction(c)
+					return nil
+				},
+			},
+		},
+	}
+	return cmd
+}
+
 func enableCommand() gcli.Command {
 	commandFlags := []gcli.Flag{
-		gcli.StringFlag{
+		gcli.StringSliceFlag{
 			Name:  "id",
-			Usage: "Id of the integration/policy that will be enabled. Either id or name must be provided",
+			Usage: "Id of the integration/policy that will be enabled. Can be given multiple times",
 		},
-		gcli.StringFlag{
+		gcli.StringSliceFlag{
 			Name:  "name",
-			Usage: "Name of the integration/policy that will be enabled. Either id or name must be provided",
+			Usage: "Name of the integration/policy that will be enabled. Can be given multiple times",
+		},
+		gcli.StringFlag{
+			Name:  "name-regex",
+			Usage: "Regular expression matching the names of the integrations/policies that will be enabled",
+		},
+		gcli.StringFlag{
+			Name:  "from-file",
+			Usage: "File listing the names of the integrations/policies that will be enabled, one per line",
+		},
+		gcli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Prints the integrations/policies that would be enabled without changing them",
 		},
 		gcli.StringFlag{
 			Name:  "type",
@@ -1098,18 +1258,38 @@ func enableCommand() gcli.Command {
 
 func disableCommand() gcli.Command {
 	commandFlags := []gcli.Flag{
-		gcli.StringFlag{
+		gcli.StringSliceFlag{
 			Name:  "id",
-			Usage: "Id of the integration/policy that will be disabled. Either id or name must be provided",
+			Usage: "Id of the integration/policy that will be disabled. Can be given multiple times",
 		},
-		gcli.StringFlag{
+		gcli.StringSliceFlag{
 			Name:  "name",
-			Usage: "Name of the integration/policy that will be disabled. Either id or name must be provided",
+			Usage: "Name of the integration/policy that will be disabled. Can be given multiple times",
+		},
+		gcli.StringFlag{
+			Name:  "name-regex",
+			Usage: "Regular expression matching the names of the integrations/policies that will be disabled",
+		},
+		gcli.StringFlag{
+			Name:  "from-file",
+			Usage: "File listing the names of the integrations/policies that will be disabled, one per line",
+		},
+		gcli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Prints the integrations/policies that would be disabled without changing them",
 		},
 		gcli.StringFlag{
 			Name:  "type",
 			Usage: "integration or policy",
 		},
+		gcli.StringFlag{
+			Name:  "for",
+			Usage: "Re-enables the integration/policy after the given duration, such as 30m or 2h. Requires 'lamp reconcile' to run periodically",
+		},
+		gcli.StringFlag{
+			Name:  "until",
+			Usage: "Re-enables the integration/policy after the given time in ISO8601 format. Requires 'lamp reconcile' to run periodically",
+		},
 	}
 	flags := append(commonFlags, commandFlags...)
 	cmd := gcli.Command{Name: "disable",
@@ -1122,6 +1302,200 @@ func disableCommand() gcli.Command {
 	return cmd
 }
 
+func reconcileCommand() gcli.Command {
+	cmd := gcli.Command{Name: "reconcile",
+		Flags: commonFlags,
+		Usage: "Re-enables the integrations and policies disabled with --for or --until whose time has expired. Meant to run periodically, e.g. from cron",
+		Action: func(c *gcli.Context) error {
+			command.ReconcileAction(c)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func maintenanceCommand() gcli.Command {
+	commandFlags := []gcli.Flag{
+		gcli.StringSliceFlag{
+			Name:  "integration",
+			Usage: "Name of an integration to disable while the command runs. Can be given multiple times",
+		},
+		gcli.StringSliceFlag{
+			Name:  "policy",
+			Usage: "Name of a policy to disable while the command runs. Can be given multiple times",
+		},
+		gcli.StringFlag{
+			Name:  "max-duration",
+			Usage: "If lamp is killed before restoring, 'lamp reconcile' re-enables everything after this duration. Default is 6h",
+		},
+	}
+	flags := append(commonFlags, commandFlags...)
+	cmd := gcli.Command{Name: "maintenance",
+		Flags:     flags,
+		Usage:     "Disables integrations and policies while running a command, then restores their state",
+		ArgsUsage: "-- command [arguments...]",
+		Action: func(c *gcli.Context) error {
+			command.MaintenanceAction(c)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func listIntegrationsCommand() gcli.Command {
+	commandFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "integrationType",
+			Usage: "Lists only the integrations of the given type, such as API or Email",
+		},
+		gcli.StringFlag{
+			Name:  "teamName",
+			Usage: "Lists only the integrations owned by the given team",
+		},
+	}
+	flags := append(append(commonFlags, commandFlags...), outputFlags...)
+	cmd := gcli.Command{Name: "listIntegrations",
+		Flags: flags,
+		Usage: "Lists OpsGenie Integrations",
+		Action: func(c *gcli.Context) error {
+			command.ListIntegrationsAction(c)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func getIntegrationCommand() gcli.Command {
+	commandFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "id",
+			Usage: "Id of the integration that will be retrieved. Either id or name must be provided",
+		},
+		gcli.StringFlag{
+			Name:  "name",
+			Usage: "Name of the integration that will be retrieved. Either id or name must be provided",
+		},
+	}
+	flags := append(append(commonFlags, commandFlags...), outputFlags...)
+	cmd := gcli.Command{Name: "getIntegration",
+		Flags: flags,
+		Usage: "Gets an OpsGenie Integration",
+		Action: func(c *gcli.Context) error {
+			command.GetIntegrationAction(c)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func listPoliciesCommand() gcli.Command {
+	commandFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "teamId",
+			Usage: "Lists the policies of the given team instead of the global policies",
+		},
+	}
+	flags := append(append(commonFlags, commandFlags...), outputFlags...)
+	cmd := gcli.Command{Name: "listPolicies",
+		Flags: flags,
+		Usage: "Lists OpsGenie Policies",
+		Action: func(c *gcli.Context) error {
+			command.ListPoliciesAction(c)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func getPolicyCommand() gcli.Command {
+	commandFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "id",
+			Usage: "Id of the policy that will be retrieved. Either id or name must be provided",
+		},
+		gcli.StringFlag{
+			Name:  "name",
+			Usage: "Name of the policy that will be retrieved. Either id or name must be provided",
+		},
+		gcli.StringFlag{
+			Name:  "teamId",
+			Usage: "Id of the team, if the policy belongs to a team",
+		},
+	}
+	flags := append(append(commonFlags, commandFlags...), outputFlags...)
+	cmd := gcli.Command{Name: "getPolicy",
+		Flags: flags,
+		Usage: "Gets an OpsGenie Policy",
+		Action: func(c *gcli.Context) error {
+			command.GetPolicyAction(c)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func exportConfigCommand() gcli.Command {
+	commandFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "type",
+			Usage: "integration or policy",
+		},
+		gcli.StringFlag{
+			Name:  "out",
+			Usage: "Directory to write a YAML file per definition to",
+		},
+		gcli.StringFlag{
+			Name:  "teamId",
+			Usage: "Exports the policies of the given team instead of the global policies",
+		},
+	}
+	flags := append(commonFlags, commandFlags...)
+	cmd := gcli.Command{Name: "exportConfig",
+		Flags: flags,
+		Usage: "Exports OpsGenie Integration or Policy definitions as YAML files",
+		Action: func(c *gcli.Context) error {
+			command.ExportConfigAction(c)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func importConfigCommand() gcli.Command {
+	commandFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "type",
+			Usage: "integration or policy",
+		},
+		gcli.StringFlag{
+			Name:  "file",
+			Usage: "YAML file holding a single definition. Either file or dir must be provided",
+		},
+		gcli.StringFlag{
+			Name:  "dir",
+			Usage: "Directory of YAML files, as written by exportConfig. Either file or dir must be provided",
+		},
+		gcli.StringFlag{
+			Name:  "teamId",
+			Usage: "Imports the policies into the given team instead of the global policies",
+		},
+		gcli.BoolFlag{
+			Name:  "diff",
+			Usage: "Prints the changes that would be made without applying them",
+		},
+	}
+	flags := append(commonFlags, commandFlags...)
+	cmd := gcli.Command{Name: "importConfig",
+		Flags: flags,
+		Usage: "Creates or updates OpsGenie Integrations or Policies from YAML files",
+		Action: func(c *gcli.Context) error {
+			command.ImportConfigAction(c)
+			return nil
+		},
+	}
+	return cmd
+}
+
 func exportUsersCommand() gcli.Command {
 	commandFlags := []gcli.Flag{
 		gcli.StringFlag{
@@ -1130,7 +1504,28 @@ func exportUsersCommand() gcli.Command {
 		},
 		gcli.StringFlag{
 			Name:  "destinationPath",
-			Usage: "Destination path to download file to",
+			Usage: "Directory to write the result file to, when output is not given",
+		},
+		gcli.StringFlag{
+			Name:  "output",
+			Usage: "File to write the users to, or - for standard output",
+		},
+		gcli.StringFlag{
+			Name:  "output-format",
+			Value: "csv",
+			Usage: "Writes the users in csv, json or yaml formats",
+		},
+		gcli.StringFlag{
+			Name:  "columns",
+			Usage: "A comma separated list of the fields to export, in order. Default is all fields",
+		},
+		gcli.StringFlag{
+			Name:  "expand",
+			Usage: "A comma separated list of related data to add for every user. Values: teams, contacts, rules",
+		},
+		gcli.StringFlag{
+			Name:  "workers",
+			Usage: "Number of users expanded concurrently. Default is 5",
 		},
 	}
 	flags := append(commonFlags, commandFlags...)
@@ -1145,6 +1540,647 @@ func exportUsersCommand() gcli.Command {
 	return cmd
 }
 
+func importUsersCommand() gcli.Command {
+	commandFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "file",
+			Usage: "CSV file in the layout written by exportUsers. The username column is required",
+		},
+		gcli.BoolFlag{
+			Name:  "prune",
+			Usage: "Blocks the users that are not in the file. Requires --yes unless --dry-run is given",
+		},
+		gcli.BoolFlag{
+			Name:  "yes",
+			Usage: "Confirms blocking the users found by --prune",
+		},
+		gcli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Prints the changes that would be made without applying them",
+		},
+	}
+	flags := append(commonFlags, commandFlags...)
+	cmd := gcli.Command{Name: "importUsers",
+		Flags: flags,
+		Usage: "Creates and updates users at OpsGenie from a CSV file",
+		Action: func(c *gcli.Context) error {
+			command.ImportUsersAction(c)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func usersCommand() gcli.Command {
+	identifierFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "id",
+			Usage: "Id of the user. Either id or username must be provided",
+		},
+		gcli.StringFlag{
+			Name:  "username",
+			Usage: "Username (e-mail address) of the user. Either id or username must be provided",
+		},
+	}
+	attributeFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "fullName",
+			Usage: "Full name of the user",
+		},
+		gcli.StringFlag{
+			Name:  "role",
+			Usage: "Role of the user, such as User, Admin or a custom role name",
+		},
+		gcli.StringFlag{
+			Name:  "timezone",
+			Usage: "Timezone of the user, such as Europe/Istanbul",
+		},
+		gcli.StringFlag{
+			Name:  "locale",
+			Usage: "Locale of the user, such as en_US",
+		},
+		gcli.StringFlag{
+			Name:  "skypeUsername",
+			Usage: "Skype username of the user",
+		},
+		gcli.StringFlag{
+			Name:  "tags",
+			Usage: "A comma separated list of labels attached to the user",
+		},
+		gcli.StringFlag{
+			Name:  "country",
+			Usage: "Country of the user address",
+		},
+		gcli.StringFlag{
+			Name:  "state",
+			Usage: "State of the user address",
+		},
+		gcli.StringFlag{
+			Name:  "city",
+			Usage: "City of the user address",
+		},
+		gcli.StringFlag{
+			Name:  "line",
+			Usage: "Street line of the user address",
+		},
+		gcli.StringFlag{
+			Name:  "zipcode",
+			Usage: "Zip code of the user address",
+		},
+	}
+	listFlags := append(append(commonFlags, gcli.StringFlag{
+		Name:  "query",
+		Usage: "Search query to apply while filtering the users",
+	}), outputFlags...)
+	getFlags := append(append(commonFlags, identifierFlags...), outputFlags...)
+	createFlags := append(append(commonFlags, gcli.StringFlag{
+		Name:  "username",
+		Usage: "Username (e-mail address) of the user",
+	}, gcli.BoolFlag{
+		Name:  "invitationDisabled",
+		Usage: "Does not send an invitation e-mail to the user",
+	}), attributeFlags...)
+	updateFlags := append(append(commonFlags, identifierFlags...), attributeFlags...)
+	deleteFlags := append(commonFlags, identifierFlags...)
+
+	cmd := gcli.Command{Name: "users",
+		Usage: "Manages OpsGenie users",
+		Subcommands: []gcli.Command{
+			{
+				Name:  "list",
+				Flags: listFlags,
+				Usage: "Lists the users at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.ListUsersAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "get",
+				Flags: getFlags,
+				Usage: "Gets a user from OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.GetUserAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "create",
+				Flags: createFlags,
+				Usage: "Creates a user at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.CreateUserAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "update",
+				Flags: updateFlags,
+				Usage: "Updates a user at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.UpdateUserAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "delete",
+				Flags: deleteFlags,
+				Usage: "Deletes a user at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.DeleteUserAction(c)
+					return nil
+				},
+			},
+		},
+	}
+	return cmd
+}
+
+func teamsCommand() gcli.Command {
+	identifierFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "id",
+			Usage: "Id of the team. Either id or name must be provided",
+		},
+		gcli.StringFlag{
+			Name:  "name",
+			Usage: "Name of the team. Either id or name must be provided",
+		},
+	}
+	descriptionFlag := gcli.StringFlag{
+		Name:  "description",
+		Usage: "Description of the team",
+	}
+	membersFlag := gcli.StringFlag{
+		Name:  "members",
+		Usage: "A comma separated list of members in the form username[:role]. Role is user by default",
+	}
+	usernameFlag := gcli.StringSliceFlag{
+		Name:  "username",
+		Usage: "Username of the member. Can be given multiple times",
+	}
+	listFlags := append(commonFlags, outputFlags...)
+	getFlags := append(append(commonFlags, identifierFlags...), outputFlags...)
+	createFlags := append(commonFlags, gcli.StringFlag{
+		Name:  "name",
+		Usage: "Name of the team",
+	}, descriptionFlag, membersFlag)
+	updateFlags := append(append(commonFlags, identifierFlags...), gcli.StringFlag{
+		Name:  "rename",
+		Usage: "New name of the team",
+	}, descriptionFlag, membersFlag)
+	deleteFlags := append(commonFlags, identifierFlags...)
+	addMemberFlags := append(append(commonFlags, identifierFlags...), usernameFlag, gcli.StringFlag{
+		Name:  "role",
+		Usage: "Role of the added members, such as user or admin. Default is user",
+	})
+	removeMemberFlags := append(append(commonFlags, identifierFlags...), usernameFlag)
+
+	cmd := gcli.Command{Name: "teams",
+		Usage: "Manages OpsGenie teams",
+		Subcommands: []gcli.Command{
+			{
+				Name:  "list",
+				Flags: listFlags,
+				Usage: "Lists the teams at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.ListTeamsAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "get",
+				Flags: getFlags,
+				Usage: "Gets a team with its members from OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.GetTeamAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "create",
+				Flags: createFlags,
+				Usage: "Creates a team at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.CreateTeamAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "update",
+				Flags: updateFlags,
+				Usage: "Updates a team at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.UpdateTeamAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "delete",
+				Flags: deleteFlags,
+				Usage: "Deletes a team at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.DeleteTeamAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "members",
+				Usage: "Manages the members of an OpsGenie team",
+				Subcommands: []gcli.Command{
+					{
+						Name:  "add",
+						Flags: addMemberFlags,
+						Usage: "Adds users to a team, or changes their role if they are already members",
+						Action: func(c *gcli.Context) error {
+							command.AddTeamMembersAction(c)
+							return nil
+						},
+					},
+					{
+						Name:  "remove",
+						Flags: removeMemberFlags,
+						Usage: "Removes users from a team",
+						Action: func(c *gcli.Context) error {
+							command.RemoveTeamMembersAction(c)
+							return nil
+						},
+					},
+				},
+			},
+		},
+	}
+	return cmd
+}
+
+func oncallCommand() gcli.Command {
+	commandFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "schedule",
+			Usage: "Name of the schedule",
+		},
+		gcli.StringFlag{
+			Name:  "scheduleId",
+			Usage: "Id of the schedule",
+		},
+		gcli.StringFlag{
+			Name:  "team",
+			Usage: "Name or id of a team. The on-call participants of every schedule owned by the team are shown",
+		},
+		gcli.StringFlag{
+			Name:  "at",
+			Usage: "Time to show the on-call participants for, such as +2h, \"2017-06-01 09:00\" or an RFC3339 time. Default is now",
+		},
+		gcli.BoolFlag{
+			Name:  "flat",
+			Usage: "Shows only the usernames of the on-call users instead of the participant hierarchy",
+		},
+	}
+	flags := append(append(commonFlags, commandFlags...), outputFlags...)
+	cmd := gcli.Command{Name: "oncall",
+		Flags: flags,
+		Usage: "Shows the current and next on-call participants of a schedule",
+		Action: func(c *gcli.Context) error {
+			command.OnCallAction(c)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func schedulesCommand() gcli.Command {
+	identifierFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "id",
+			Usage: "Id of the schedule. Either id or name must be provided",
+		},
+		gcli.StringFlag{
+			Name:  "name",
+			Usage: "Name of the schedule. Either id or name must be provided",
+		},
+	}
+	rangeFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "from",
+			Usage: "Start of the range, such as today, -1d, \"2017-06-01 09:00\" or an RFC3339 time. Default is now",
+		},
+		gcli.StringFlag{
+			Name:  "to",
+			Usage: "End of the range, in the same forms as from. Default is a week after from",
+		},
+	}
+	listFlags := append(commonFlags, outputFlags...)
+	getFlags := append(append(commonFlags, identifierFlags...), outputFlags...)
+	timelineFlags := append(append(append(commonFlags, identifierFlags...), rangeFlags...), outputFlags...)
+	exportFlags := append(append(append(commonFlags, identifierFlags...), rangeFlags...),
+		gcli.StringFlag{
+			Name:  "format",
+			Value: "ics",
+			Usage: "Format of the exported file. Only ics is supported",
+		},
+		gcli.StringFlag{
+			Name:  "output",
+			Usage: "File to write the schedule to, or - for standard output. Default is <schedule name>.ics",
+		},
+	)
+
+	cmd := gcli.Command{Name: "schedules",
+		Usage: "Inspects and exports OpsGenie schedules",
+		Subcommands: []gcli.Command{
+			{
+				Name:  "list",
+				Flags: listFlags,
+				Usage: "Lists the schedules at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.ListSchedulesAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "get",
+				Flags: getFlags,
+				Usage: "Gets a schedule with its rotations from OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.GetScheduleAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "timeline",
+				Flags: timelineFlags,
+				Usage: "Shows the on-call shifts of a schedule for a date range",
+				Action: func(c *gcli.Context) error {
+					command.ScheduleTimelineAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "export",
+				Flags: exportFlags,
+				Usage: "Exports the on-call shifts of a schedule for a date range to an iCalendar file",
+				Action: func(c *gcli.Context) error {
+					command.ExportScheduleAction(c)
+					return nil
+				},
+			},
+		},
+	}
+	return cmd
+}
+
+func overridesCommand() gcli.Command {
+	scheduleFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "schedule",
+			Usage: "Name of the schedule. Either schedule or scheduleId must be provided",
+		},
+		gcli.StringFlag{
+			Name:  "scheduleId",
+			Usage: "Id of the schedule. Either schedule or scheduleId must be provided",
+		},
+	}
+	createFlags := append(append(commonFlags, scheduleFlags...),
+		gcli.StringFlag{
+			Name:  "username",
+			Usage: "Username of the user to put on call",
+		},
+		gcli.StringFlag{
+			Name:  "start",
+			Usage: "Start of the override, such as now, +1h, \"2017-06-01 09:00\" or an RFC3339 time",
+		},
+		gcli.StringFlag{
+			Name:  "end",
+			Usage: "End of the override, in the same forms as start",
+		},
+		gcli.StringFlag{
+			Name:  "alias",
+			Usage: "Alias of the override. Generated by OpsGenie if not given",
+		},
+		gcli.StringSliceFlag{
+			Name:  "rotation",
+			Usage: "Name of a rotation to override. Can be given multiple times. All rotations are overridden by default",
+		},
+	)
+	listFlags := append(append(commonFlags, scheduleFlags...), outputFlags...)
+	deleteFlags := append(append(commonFlags, scheduleFlags...), gcli.StringFlag{
+		Name:  "alias",
+		Usage: "Alias of the override",
+	})
+
+	cmd := gcli.Command{Name: "overrides",
+		Usage: "Manages the overrides of OpsGenie schedules",
+		Subcommands: []gcli.Command{
+			{
+				Name:  "create",
+				Flags: createFlags,
+				Usage: "Puts a user on call in place of the scheduled participants for a period",
+				Action: func(c *gcli.Context) error {
+					command.CreateOverrideAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "list",
+				Flags: listFlags,
+				Usage: "Lists the overrides of a schedule",
+				Action: func(c *gcli.Context) error {
+					command.ListOverridesAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "delete",
+				Flags: deleteFlags,
+				Usage: "Deletes an override of a schedule",
+				Action: func(c *gcli.Context) error {
+					command.DeleteOverrideAction(c)
+					return nil
+				},
+			},
+		},
+	}
+	return cmd
+}
+
+func escalationsCommand() gcli.Command {
+	identifierFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "id",
+			Usage: "Id of the escalation. Either id or name must be provided",
+		},
+		gcli.StringFlag{
+			Name:  "name",
+			Usage: "Name of the escalation. Either id or name must be provided",
+		},
+	}
+	definitionFlags := []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "description",
+			Usage: "Description of the escalation",
+		},
+		gcli.StringFlag{
+			Name:  "ownerTeam",
+			Usage: "Name of the team that owns the escalation",
+		},
+		gcli.StringSliceFlag{
+			Name: "rule",
+			Usage: "Rule of the escalation in the form \"DELAY CONDITION TYPE[:NAME] [NOTIFY_TYPE]\". Can be given multiple times, in order.\n\t" +
+				"Syntax: --rule \"0m if-not-acked schedule:primary\" --rule \"15m if-not-closed team:ops admins\"",
+		},
+	}
+	listFlags := append(commonFlags, outputFlags...)
+	getFlags := append(append(commonFlags, identifierFlags...), outputFlags...)
+	createFlags := append(append(commonFlags, gcli.StringFlag{
+		Name:  "name",
+		Usage: "Name of the escalation",
+	}), definitionFlags...)
+	updateFlags := append(append(append(commonFlags, identifierFlags...), gcli.StringFlag{
+		Name:  "rename",
+		Usage: "New name of the escalation",
+	}), definitionFlags...)
+	deleteFlags := append(commonFlags, identifierFlags...)
+
+	cmd := gcli.Command{Name: "escalations",
+		Usage: "Manages OpsGenie escalations",
+		Subcommands: []gcli.Command{
+			{
+				Name:  "list",
+				Flags: listFlags,
+				Usage: "Lists the escalations at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.ListEscalationsAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "get",
+				Flags: getFlags,
+				Usage: "Gets an escalation with its rules from OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.GetEscalationAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "create",
+				Flags: createFlags,
+				Usage: "Creates an escalation at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.CreateEscalationAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "update",
+				Flags: updateFlags,
+				Usage: "Updates an escalation at OpsGenie. The rules are replaced when any rule is given",
+				Action: func(c *gcli.Context) error {
+					command.UpdateEscalationAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "delete",
+				Flags: deleteFlags,
+				Usage: "Deletes an escalation at OpsGenie",
+				Action: func(c *gcli.Context) error {
+					command.DeleteEscalationAction(c)
+					return nil
+				},
+			},
+		},
+	}
+	return cmd
+}
+
+func profilesCommand() gcli.Command {
+	flags := append(commonFlags, outputFlags...)
+	cmd := gcli.Command{Name: "profiles",
+		Flags: flags,
+		Usage: "Lists the profiles defined in the configuration file",
+		Action: func(c *gcli.Context) error {
+			command.ListProfilesAction(c)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func pingCommand() gcli.Command {
+	flags := append(commonFlags, outputFlags...)
+	cmd := gcli.Command{Name: "ping",
+		Flags: flags,
+		Usage: "Prints the API endpoint requests are sent to and checks that the API key is accepted there",
+		Action: func(c *gcli.Context) error {
+			command.PingAction(c)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func configCommand() gcli.Command {
+	initFlags := append(commonFlags, gcli.BoolFlag{
+		Name:  "force",
+		Usage: "Overwrites the configuration file if it exists",
+	})
+	viewFlags := append(commonFlags, outputFlags...)
+
+	cmd := gcli.Command{Name: "config",
+		Usage: "Manages the lamp configuration file",
+		Subcommands: []gcli.Command{
+			{
+				Name:  "init",
+				Flags: initFlags,
+				Usage: "Asks for the basic settings and writes a new configuration file to --config or $XDG_CONFIG_HOME/lamp/lamp.conf",
+				Action: func(c *gcli.Context) error {
+					command.ConfigInitAction(c)
+					return nil
+				},
+			},
+			{
+				Name:      "get",
+				Flags:     commonFlags,
+				ArgsUsage: "key",
+				Usage:     "Prints the effective value of a key",
+				Action: func(c *gcli.Context) error {
+					command.ConfigGetAction(c)
+					return nil
+				},
+			},
+			{
+				Name:      "set",
+				Flags:     commonFlags,
+				ArgsUsage: "key value",
+				Usage:     "Sets a key in the configuration file, or in the section of --profile, keeping its comments",
+				Action: func(c *gcli.Context) error {
+					command.ConfigSetAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "view",
+				Flags: viewFlags,
+				Usage: "Prints the effective configuration and the source of every value, with secrets redacted",
+				Action: func(c *gcli.Context) error {
+					command.ConfigViewAction(c)
+					return nil
+				},
+			},
+			{
+				Name:  "validate",
+				Flags: commonFlags,
+				Usage: "Checks the configuration file",
+				Action: func(c *gcli.Context) error {
+					command.ConfigValidateAction(c)
+					return nil
+				},
+			},
+		},
+	}
+	return cmd
+}
+
 func initCommands(app *gcli.App) {
 	app.Commands = []gcli.Command{
 		createAlertCommand(),
@@ -1166,8 +2202,17 @@ func initCommands(app *gcli.App) {
 		closeAlertCommand(),
 		deleteAlertCommand(),
 		heartbeatCommand(),
+		heartbeatsCommand(),
 		enableCommand(),
 		disableCommand(),
+		reconcileCommand(),
+		maintenanceCommand(),
+		listIntegrationsCommand(),
+		getIntegrationCommand(),
+		listPoliciesCommand(),
+		getPolicyCommand(),
+		exportConfigCommand(),
+		importConfigCommand(),
 		listAlertsCommand(),
 		countAlertsCommand(),
 		listAlertNotesCommand(),
@@ -1180,6 +2225,16 @@ func initCommands(app *gcli.App) {
 		removeDetailsCommand(),
 		escalateToNextActionCommand(),
 		exportUsersCommand(),
+		importUsersCommand(),
+		usersCommand(),
+		teamsCommand(),
+		oncallCommand(),
+		schedulesCommand(),
+		overridesCommand(),
+		escalationsCommand(),
+		profilesCommand(),
+		configCommand(),
+		pingCommand(),
 	}
 }
 
@@ -1194,7 +2249,7 @@ func main() {
 		return nil
 	}
 	initCommands(app)
-	err := app.Run(os.Args)
+	err := app.Run(command.JoinConditionArgs(os.Args))
 	if err != nil {
 		fmt.Printf("Error occured while executing command: %s\n", err.Error())
 	}